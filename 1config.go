@@ -28,6 +28,30 @@ func init() {
 	} else {
 		configPath = exPath + "/" + configPath
 	}
+	err = validateTiers(configuration.Tiers)
+	if err != nil {
+		panic(err)
+	}
+	err = loadRankPolicy(configuration.Ranks)
+	if err != nil {
+		panic(err)
+	}
+	err = validateAttendanceGates(configuration.Bids.AttendanceGates)
+	if err != nil {
+		panic(err)
+	}
+	_, err = getPricing(configuration.Bids.Pricing)
+	if err != nil {
+		panic(err)
+	}
+	err = validateAudit(configuration.Audit)
+	if err != nil {
+		panic(err)
+	}
+	err = validateRoutes(configuration.Routes)
+	if err != nil {
+		panic(err)
+	}
 }
 
 type Main struct {
@@ -45,16 +69,26 @@ type SpellOverride struct {
 }
 
 type Bids struct {
-	OpenBidTimer           int    `comment:"Number of minutes to keep bids open before auto closing"`
-	MinimumBid             int    `comment:"Minimum bid accepted - 10"`
-	Increments             int    `comment:"Increment multiple for bids - 5"`
-	SecondMainAsMainMaxBid int    `comment:"Max value that bids can bid against mains, set to 0 for infinite"`
-	MaxBid                 int    `comment:"Max bid allowed for a single item needs to be lower to allow rounding down default: 9000"`
-	RegexClosedBid         string `comment:"Regex to detect a bid has been closed"`
-	RegexOpenBid           string `comment:"Regex to detect a bid has been opened"`
-	RegexTellBid           string `comment:"Regex to detect a bid being sent via tell"`
-	CloseAutomatically     bool   `comment:"Close bids automatically after timer has expired"`
-	SecondMainsBidAsMains  bool   `comment:"Will second mains be tiered the same as mains"`
+	OpenBidTimer           int               `comment:"Number of minutes to keep bids open before auto closing"`
+	MinimumBid             int               `comment:"Minimum bid accepted - 10"`
+	Increments             int               `comment:"Increment multiple for bids - 5"`
+	SecondMainAsMainMaxBid int               `comment:"Max value that bids can bid against mains, set to 0 for infinite"`
+	MaxBid                 int               `comment:"Max bid allowed for a single item needs to be lower to allow rounding down default: 9000"`
+	RegexClosedBid         string            `comment:"Regex to detect a bid has been closed"`
+	RegexOpenBid           string            `comment:"Regex to detect a bid has been opened"`
+	RegexTellBid           string            `comment:"Regex to detect a bid being sent via tell"`
+	CloseAutomatically     bool              `comment:"Close bids automatically after timer has expired"`
+	SecondMainsBidAsMains  bool              `comment:"Will second mains be tiered the same as mains"`
+	WriteSpentDKP          bool              `comment:"Write winning bids to the DKP ledger instead of only posting them"`
+	Pricing                string            `comment:"How winners are charged: second-price, first-price, fixed-price or zero-sum"`
+	FixedPrice             int               `comment:"Price per item for fixed-price, 0 uses MinimumBid"`
+	RollOffSeconds         int               `comment:"Seconds tied bidders have to /rand 1000 before they forfeit, 0 uses 120"`
+	AttendanceWeighted     bool              `comment:"Gate tiers on attendance with AttendanceGates and break ties on 30 day attendance before rolling"`
+	AttendanceGates        []AttendanceGate  `comment:"Attendance minimums per tier, used when AttendanceWeighted is on"`
+	JournalPath            string            `comment:"File open bids are saved to so they survive a restart, empty to disable"`
+	FuzzyDistance          int               `comment:"Typos allowed when matching a tell to an item name, 0 scales with the name length"`
+	Abbreviations          map[string]string `comment:"Abbreviations expanded when matching tells to item names, lowercase short = long e.g. bp = \"breastplate\""`
+	ProxyBidders           []string          `comment:"Characters or guild ranks allowed to bid for another member with a tell like: Item for Mortimus 50"`
 }
 
 type Discord struct {
@@ -63,6 +97,7 @@ type Discord struct {
 	InvestigationChannelID   string   `comment:"Discord Channel to sent investigations to"`
 	LootIcon                 string   `comment:"Icon used for the loot in discord"`
 	InvestigationStartEmoji  string   `comment:"Emoji response used to start an investigation"`
+	RevertSpentEmoji         string   `comment:"Emoji response used to revert a spent DKP entry"`
 	GuildID                  string   `comment:"Discord Guild ID"`
 	RaidDumpChannelID        string   `comment:"Discord channel to send raid dumps to"`
 	SpellDumpChannelID       string   `comment:"Discord channel to send spell loot to"`
@@ -70,7 +105,11 @@ type Discord struct {
 	ParseChannelID           string   `comment:"Discord channel to send parses to"`
 	DKPArchiveChannelID      string   `comment:"Discord channel to send dkp backups to"`
 	UseDiscord               bool     `comment:"Should we use discord"`
+	EnableCommands           bool     `comment:"Register slash commands for dkp lookup and bidding"`
+	LinkPath                 string   `comment:"File discord account to character links are saved to, empty keeps links in memory only"`
 	InvestigationMinRequired int      `comment:"Number of reactions required to start investigation"`
+	InvestigationHTML        bool     `comment:"Attach a readable HTML report to investigations along with the summary"`
+	OutboxSize               int      `comment:"Messages waiting per channel or webhook before new ones are dropped, 0 uses 100"`
 	PrivRoles                []string `comment:"Discord roles that are considered privledged, for starting investigations"`
 }
 
@@ -78,8 +117,8 @@ type Everquest struct {
 	LogPath           string   `comment:"path to character log file"`
 	ItemDB            string   `comment:"path to the eqitems item database"`
 	SpellDB           string   `comment:"path to the lucydb spell database"`
-	RegexIsAlt        string   `comment:"Regex to determine if character is an alt"`
-	RegexIsSecondMain string   `comment:"Regex to determine if character is a 2nd main"`
+	RegexIsAlt        string   `comment:"Regex on the public note marking a character as an alt, in addition to the in game alt flag"`
+	RegexIsSecondMain string   `comment:"Regex on an alt's public note marking it a 2nd main when no Ranks rules are set"`
 	GuildName         string   `comment:"Guild name to determine guild dumps"`
 	BaseFolder        string   `comment:"Base folder where eqgame.exe is located, for determining logs and dumps"`
 	RegexLoot         string   `comment:"Regex to detect when an item has been looted"`
@@ -87,11 +126,12 @@ type Everquest struct {
 	ParseChannel      string   `comment:"everquest channel to monitor for parses"`
 	RegexSlay         string   `comment:"Regex to detect when a mob is slain"`
 	RegexRoll         string   `comment:"Regex to detect when a does a die roll"`
-	GuildRaidingRanks []string `comment:"Guild Ranks that can bid on items"`
+	GuildRaidingRanks []string `comment:"Guild Ranks that bid as mains when no Ranks rules are set"`
 	FlagGiver         []string `comment:"log text for a character getting a flag - Hail, a planar projection"`
 	DKPGiver          []string `comment:"mob names that we apply DKP for"`
 	SpellProvider     []string `comment:"item that provides a spell like Spectral Parchment"`
 	MissingItemsPath  string   `comment:"path to the missing items file"`
+	TellQueuePath     string   `comment:"File bid acknowledgements are written to as /tell commands for a macro or helper to send, empty disables"`
 }
 
 type Google struct {
@@ -133,11 +173,38 @@ type Sheets struct {
 	DKPSummarySheetDKPCol    int    `comment:"Google sheet sheet column for dkp count"`
 }
 
+type Store struct {
+	Backend     string `comment:"Where the DKP ledger is kept: sheets or file"`
+	LedgerPath  string `comment:"File backend: csv of the raw DKP/Attendance sheet"`
+	BossesPath  string `comment:"File backend: csv of the bosses sheet"`
+	SpellFolder string `comment:"File backend: folder holding a <Class>.csv spell sheet per class"`
+}
+
 type Log struct {
 	Level int    `comment:"How much to log Warn:0 Err:1 Info:2 Debug:3"`
 	Path  string `comment:"Where to store the log file use linux formatting or escape slashes for windows"`
 }
 
+type Audit struct {
+	InvestigateSeverity string            `comment:"Lowest audit severity that starts an investigation automatically: info, warning or critical (default)"`
+	Disabled            []string          `comment:"Audit rules to skip: cannot-equip, zero-dkp-win, rot-spent, outside-window, not-in-raid, alt-and-main, duplicate-main, over-dkp"`
+	Severities          map[string]string `comment:"Severity overrides by rule name e.g. not-in-raid = \"critical\""`
+}
+
+type PluginConfig struct {
+	Name      string            `comment:"Plugin to load: bids, loot, flags, raid, guild, linkdead, roll, zone, link or parse"`
+	Disabled  bool              `comment:"Keep the plugin from loading"`
+	Output    string            `comment:"Route the plugin writes to: stdout, bids, investigations, raid, spells, flags, parses or one from Routes. Empty keeps its default"`
+	Settings  map[string]string `comment:"Plugin settings table e.g. channel = \"von_parses\" for parse"`
+	Username  string            `comment:"Name the plugin posts as on webhook sinks, empty uses the webhook's name"`
+	AvatarURL string            `comment:"Avatar image url the plugin posts with on webhook sinks"`
+}
+
+type Route struct {
+	Name  string   `comment:"Route name for a plugin's Output, a built in name (bids, investigations, raid, spells, flags, parses, dumps, stdout) replaces that route"`
+	Sinks []string `comment:"Where the route writes, any of: stdout, discord:<channel id>, webhook:<url> or file:<path>. Raid and guild dump files go to the dumps route"`
+}
+
 type Configuration struct {
 	Main      Main
 	Everquest Everquest
@@ -146,7 +213,14 @@ type Configuration struct {
 	Discord   Discord
 	Google    Google
 	Sheets    Sheets
+	Store     Store
+	Audit     Audit
 	Overrides []SpellOverride `comment:"Spell that finds as wrong ID, force an ID here"`
+	Ranks     []RankRule      `comment:"Rules mapping guild ranks and public notes to DKP tiers, first match wins. Empty uses GuildRaidingRanks and RegexIsSecondMain"`
+	ItemRules []ItemRule      `comment:"Bid rules for single items or whole zones, matched by ItemID, then Item, then Zone"`
+	Tiers     []Tier          `comment:"Bidding tiers and their priority. Empty uses Main > Second Main > Recruit > Alt > Social > Inactive with the SecondMain bid settings"`
+	Plugins   []PluginConfig  `comment:"Plugins to load and where they write. Empty loads every plugin with its default output"`
+	Routes    []Route         `comment:"Named outputs that can write to several discord channels, webhooks, files or stdout at once"`
 }
 
 func loadConfig(path string) (Configuration, error) {
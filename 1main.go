@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -11,13 +10,12 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	everquest "github.com/Mortimus/goEverquest"
 	"github.com/bwmarrin/discordgo"
-	"golang.org/x/oauth2/google"
-	"google.golang.org/api/sheets/v4"
 )
 
 var printChan = make(chan string)
@@ -27,10 +25,12 @@ var spellDB everquest.SpellDB
 
 var investigation Investigation
 var currentTime time.Time // for simulating time
-var archives []string     // stores all known archive files for recall
 
 var Debug, Warn, Err, Info *log.Logger
 
+// searchOnly is set for the search subcommand, it is known before any init runs so setup that needs the network or the game can be skipped
+var searchOnly = len(os.Args) > 1 && os.Args[1] == "search"
+
 func init() {
 	// Initialize log handlers
 	LogFile, err := os.OpenFile(configuration.Log.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
@@ -53,6 +53,10 @@ func init() {
 	if configuration.Log.Level < 3 {
 		Debug.SetOutput(ioutil.Discard)
 	}
+	archiveIndex = buildArchiveIndex()
+	if searchOnly {
+		return // search only reads the archive folder, skip the item databases and the dkp store
+	}
 	itemDB.LoadFromFile(configuration.Everquest.ItemDB, Err, Info)
 	// Load dummy items
 	err = loadDummyItems(configuration.Everquest.MissingItemsPath)
@@ -61,43 +65,26 @@ func init() {
 	}
 	spellDB.LoadFromFile(configuration.Everquest.SpellDB, Err)
 
-	archives = getArchiveList()
 	// loadRoster(configuration.GuildRosterPath)
 
-	// Setup google sheets
-	gtoken := &Gtoken{
-		Installed: Inst{
-			ClientID:                configuration.Google.ClientID,
-			ProjectID:               configuration.Google.ProjectID,
-			AuthURI:                 configuration.Google.AuthURI,
-			TokenURI:                configuration.Google.TokenURI,
-			AuthProviderx509CertURL: configuration.Google.AuthProviderx509CertURL,
-			ClientSecret:            configuration.Google.ClientSecret,
-			RedirectURIs:            configuration.Google.RedirectURIs,
-		},
-	}
-	Info.Printf("Marshalling gToken: %+v", gtoken)
-	bToken, err := json.Marshal(gtoken)
-	if err != nil {
-		Err.Fatalf("error marshalling gtoken")
-	}
-
-	// If modifying these scopes, delete your previously saved token.json.
-	config, err := google.ConfigFromJSON(bToken, "https://www.googleapis.com/auth/spreadsheets")
+	dkpStore, err = newDKPStore(configuration.Store.Backend)
 	if err != nil {
-		Err.Fatalf("Unable to parse client secret file to config: %v", err)
+		Err.Fatalf("Unable to setup dkp store: %v", err)
 	}
-	client := getClient(config)
-
-	srv, err = sheets.New(client)
-	if err != nil {
-		Err.Fatalf("Unable retrieve Sheets client: %v", err)
+	if _, ok := dkpStore.(*SheetsStore); ok {
+		connectSheets()
 	}
 	seedBosses()
 }
 
 func main() {
-	var err error
+	if searchOnly {
+		os.Exit(runSearch(os.Args[2:], os.Stdout))
+	}
+	err := checkPlugins()
+	if err != nil {
+		panic(err)
+	}
 	// Create a new Discord session using the provided bot token.
 	discord, err = discordgo.New("Bot " + configuration.Discord.Token)
 	if err != nil {
@@ -129,6 +116,9 @@ func main() {
 		Err.Fatalf("Error opening connection with Discord: %v", err)
 		return
 	}
+	announceRecoveredBids()
+	registerCommands()
+	go outbox.logMetrics(outboxMetricsEvery)
 
 	// daemon.SdNotify(false, "READY=1")
 
@@ -150,6 +140,8 @@ func main() {
 	for {
 		select {
 		case <-sc:
+			Info.Printf("Shutting down, sending queued discord messages")
+			outbox.Flush(outboxFlushTimeout) // before discord.Close so nothing queued is lost
 			return
 		case pMsg := <-printChan:
 			fmt.Printf("%s", pMsg)
@@ -161,46 +153,73 @@ func main() {
 
 func parseLogs(ChatLogs chan everquest.EqLog, quit <-chan bool) {
 	Info.Printf("Parsing logs")
+	defer stopParser.Do(func() { close(parserStopped) })
 	// printHUD()
-	for msgs := range ChatLogs {
-		currentTime = msgs.T
-		if (msgs.Channel == "guild" && msgs.Source == "You") || msgs.Channel == "tell" {
-			investigation.addLog(msgs)
-			// printMessage(&msgs)
-		}
-		if msgs.Channel == "tell" || (msgs.Source == "You" && strings.Contains(msgs.Msg, "told")) {
-			printMessage(&msgs)
-		}
-		//checkClosedBids()
-		//parseLogLine(msgs) // Old, should be replaced with plugin system below
-		for _, handler := range Handlers {
-			switch handler.OutputChannel() {
-			case STDOUT:
-				handler.Handle(&msgs, os.Stdout)
-			case BIDOUT:
-				handler.Handle(&msgs, &BidWriter)
-			case INVESTIGATEOUT:
-				handler.Handle(&msgs, &InvestigateWriter)
-			case RAIDOUT:
-				handler.Handle(&msgs, &RaidWriter)
-			case SPELLOUT:
-				handler.Handle(&msgs, &SpellWriter)
-			case FLAGOUT:
-				handler.Handle(&msgs, &FlagWriter)
-			case PARSEOUT:
-				handler.Handle(&msgs, &ParseWriter)
-			default:
-				handler.Handle(&msgs, os.Stdout)
-			}
-		}
+	ticker := time.NewTicker(1 * time.Second) // lets plugins act on timers between log lines
+	defer ticker.Stop()
+	for {
 		select {
+		case msgs, ok := <-ChatLogs:
+			if !ok {
+				return
+			}
+			currentTime = msgs.T
+			if (msgs.Channel == "guild" && msgs.Source == "You") || msgs.Channel == "tell" {
+				investigation.addLog(msgs)
+				// printMessage(&msgs)
+			}
+			if msgs.Channel == "tell" || (msgs.Source == "You" && strings.Contains(msgs.Msg, "told")) {
+				printMessage(&msgs)
+			}
+			//checkClosedBids()
+			//parseLogLine(msgs) // Old, should be replaced with plugin system below
+			for _, handler := range Handlers {
+				if lHandler, ok := handler.(LineHandler); ok {
+					lHandler.Handle(&msgs, getOutput(handler))
+				}
+			}
+			bus.PublishLog(&msgs)
+		case <-ticker.C:
+			for _, handler := range Handlers {
+				if tHandler, ok := handler.(TickHandler); ok {
+					tHandler.Tick(getTime(), getOutput(handler))
+				}
+			}
+		case task := <-parserTasks:
+			task()
 		case <-quit:
 			return
-		default:
 		}
 	}
 }
 
+// parserTasks lets other goroutines, like discord handlers, touch bids and the roster without racing the log parser
+var parserTasks = make(chan func())
+
+// parserStopped is closed once parseLogs returns, nothing will take parserTasks after that
+var parserStopped = make(chan struct{})
+var stopParser sync.Once
+
+const parserTaskWait = 5 * time.Second
+
+// runOnParser runs task on the log parsing goroutine and waits for it to finish, false if the parser never took it
+func runOnParser(task func()) bool {
+	done := make(chan bool)
+	select {
+	case parserTasks <- func() {
+		task()
+		close(done)
+	}:
+	case <-parserStopped:
+		return false
+	case <-time.After(parserTaskWait):
+		Warn.Printf("Log parser did not take a task within %s", parserTaskWait)
+		return false
+	}
+	<-done // the parser runs it to the end once taken
+	return true
+}
+
 func isItem(name string) int {
 	// itemLock.Lock()
 	// defer itemLock.Unlock()
@@ -244,9 +263,15 @@ func uploadArchive(id string) {
 	}
 	if err != nil {
 		Err.Printf("Error finding archive: %s", err.Error())
-		discord.ChannelMessageSend(configuration.Discord.InvestigationChannelID, "Error uploading investigation: "+id)
+		DiscordF(configuration.Discord.InvestigationChannelID, "Error uploading investigation: %s", id)
 	} else {
-		discord.ChannelFileSend(configuration.Discord.InvestigationChannelID, id+".json", file)
+		uploadInvestigationReport(id)
+		err = discordFile(configuration.Discord.InvestigationChannelID, id+".json", file)
+		file.Close()
+		if err != nil {
+			Err.Printf("Error sending investigation %s: %s", id, err.Error())
+		}
+		uploadTimeline(id)
 	}
 }
 
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultSearchDays = 30
+
+// AuctionBidder is a character's part in an indexed auction
+type AuctionBidder struct {
+	Player string
+	Main   string
+	Bid    int // bid applied after DKP and tier caps
+	Won    bool
+	Price  int
+}
+
+// AuctionRecord is one closed auction in the archive index
+type AuctionRecord struct {
+	ID        string
+	MessageID string // discord message the bids were announced in
+	Item      string
+	Zone      string
+	Ended     time.Time
+	Price     int // winning bid
+	Bidders   []AuctionBidder
+}
+
+// Winners lists the characters that won the auction
+func (r *AuctionRecord) Winners() []string {
+	var winners []string
+	for _, bidder := range r.Bidders {
+		if bidder.Won {
+			winners = append(winners, bidder.Player)
+		}
+	}
+	return winners
+}
+
+// ArchiveIndex is every closed auction in the archive folder, newest first
+type ArchiveIndex struct {
+	Auctions []AuctionRecord
+}
+
+// archiveIndex is only changed on the parser goroutine, use runOnParser to read it from discord
+var archiveIndex = new(ArchiveIndex)
+
+// buildArchiveIndex reads every archive in the archive folder
+func buildArchiveIndex() *ArchiveIndex {
+	index := new(ArchiveIndex)
+	for _, id := range getArchiveList() {
+		arc, err := loadArchive(id)
+		if err != nil {
+			Err.Printf("Error indexing archive %s: %s", id, err.Error())
+			continue
+		}
+		index.Add(id, arc)
+	}
+	return index
+}
+
+func auctionRecord(id string, arc BidInvestigation) AuctionRecord {
+	ended, err := time.Parse(time.RFC822, arc.Ended)
+	if err != nil {
+		Warn.Printf("Archive %s has no end date: %s", id, err.Error())
+	}
+	record := AuctionRecord{ID: id, MessageID: arc.MessageID, Item: arc.ItemName, Zone: arc.Zone, Ended: ended, Price: arc.WinningBid}
+	if record.MessageID == "" {
+		record.MessageID = id // archives used to be named after the discord message
+	}
+	for _, bidder := range arc.Bidders {
+		record.Bidders = append(record.Bidders, AuctionBidder{
+			Player: bidder.Player,
+			Main:   bidder.Main,
+			Bid:    bidder.BidApplied,
+			Won:    bidder.WonOrTied,
+			Price:  bidder.Price,
+		})
+	}
+	return record
+}
+
+// Add indexes an archive, replacing it if it was already indexed
+func (index *ArchiveIndex) Add(id string, arc BidInvestigation) {
+	record := auctionRecord(id, arc)
+	for i := range index.Auctions {
+		if index.Auctions[i].ID == id {
+			index.Auctions[i] = record
+			return
+		}
+	}
+	index.Auctions = append(index.Auctions, record)
+	sort.SliceStable(index.Auctions, func(i, j int) bool { return index.Auctions[i].Ended.After(index.Auctions[j].Ended) })
+}
+
+// Has is true for auctions in the index
+func (index *ArchiveIndex) Has(id string) bool {
+	for _, record := range index.Auctions {
+		if record.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ForMessage finds the archive of the auction announced in a discord message
+func (index *ArchiveIndex) ForMessage(messageID string) (string, bool) {
+	if messageID == "" {
+		return "", false
+	}
+	for _, record := range index.Auctions {
+		if record.MessageID == messageID {
+			return record.ID, true
+		}
+	}
+	return "", false
+}
+
+// isCharacter matches a bidder by character or main name
+func (bidder *AuctionBidder) isCharacter(name string) bool {
+	return strings.EqualFold(bidder.Player, name) || strings.EqualFold(bidder.Main, name)
+}
+
+// sameItem matches item names the same way tells are matched
+func sameItem(a, b string) bool {
+	return normalizeItemName(a) == normalizeItemName(b)
+}
+
+// Won lists the auctions a character, or any character of a main, won since a time
+func (index *ArchiveIndex) Won(name string, since time.Time) []AuctionRecord {
+	var won []AuctionRecord
+	for _, record := range index.Auctions {
+		if record.Ended.Before(since) {
+			continue
+		}
+		for _, bidder := range record.Bidders {
+			if bidder.Won && bidder.isCharacter(name) {
+				won = append(won, record)
+				break
+			}
+		}
+	}
+	return won
+}
+
+// AveragePrice is the average winning price of an item over the auctions that had a winner
+func (index *ArchiveIndex) AveragePrice(item string) (float64, int) {
+	var total, count int
+	for _, record := range index.Auctions {
+		if !sameItem(record.Item, item) || len(record.Winners()) == 0 {
+			continue
+		}
+		total += record.Price
+		count++
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return float64(total) / float64(count), count
+}
+
+// Lost lists everyone that bid on an item and did not win it, with the auction they lost
+func (index *ArchiveIndex) Lost(item string) []AuctionRecord {
+	var lost []AuctionRecord
+	for _, record := range index.Auctions {
+		if !sameItem(record.Item, item) {
+			continue
+		}
+		losers := record
+		losers.Bidders = nil
+		for _, bidder := range record.Bidders {
+			if !bidder.Won {
+				losers.Bidders = append(losers.Bidders, bidder)
+			}
+		}
+		if len(losers.Bidders) > 0 {
+			lost = append(lost, losers)
+		}
+	}
+	return lost
+}
+
+// searchArchive answers a query on the index: won <name> [days], price <item> or losers <item>
+func searchArchive(index *ArchiveIndex, query string, term string, days int) (string, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return "", errors.New("search needs a character or item name")
+	}
+	var b strings.Builder
+	switch strings.ToLower(query) {
+	case "won":
+		if days <= 0 {
+			days = defaultSearchDays
+		}
+		won := index.Won(term, getTime().AddDate(0, 0, -days))
+		if len(won) == 0 {
+			return fmt.Sprintf("%s won nothing in the last %d days", term, days), nil
+		}
+		fmt.Fprintf(&b, "%s won %d items in the last %d days\n", term, len(won), days)
+		for _, record := range won {
+			for _, bidder := range record.Bidders {
+				if bidder.Won && bidder.isCharacter(term) {
+					fmt.Fprintf(&b, "%s %s: %s for %d\n", record.Ended.Format("01/02"), record.Item, bidder.Player, bidder.Price)
+				}
+			}
+		}
+	case "price":
+		average, count := index.AveragePrice(term)
+		if count == 0 {
+			return fmt.Sprintf("No auctions with a winner found for %s", term), nil
+		}
+		fmt.Fprintf(&b, "%s sold %d times for an average of %.1f DKP\n", term, count, average)
+	case "losers":
+		lost := index.Lost(term)
+		if len(lost) == 0 {
+			return fmt.Sprintf("Nobody lost a bid on %s", term), nil
+		}
+		for _, record := range lost {
+			var names []string
+			for _, bidder := range record.Bidders {
+				names = append(names, fmt.Sprintf("%s (%d)", bidder.Player, bidder.Bid))
+			}
+			fmt.Fprintf(&b, "%s %s won by %s for %d, lost: %s\n", record.Ended.Format("01/02"), record.Item, strings.Join(record.Winners(), ", "), record.Price, strings.Join(names, ", "))
+		}
+	default:
+		return "", errors.New("unknown search: " + query + ", use won, price or losers")
+	}
+	return b.String(), nil
+}
+
+// runSearch is the search subcommand: search won <name> [days] | price <item> | losers <item>
+func runSearch(args []string, out io.Writer) int {
+	if len(args) < 2 {
+		fmt.Fprintln(out, "usage: search won <name> [days] | price <item> | losers <item>")
+		return 2
+	}
+	query, term := args[0], strings.Join(args[1:], " ")
+	var days int
+	if strings.EqualFold(query, "won") && len(args) > 2 {
+		var err error
+		days, err = strconv.Atoi(args[len(args)-1])
+		if err != nil {
+			fmt.Fprintf(out, "days must be a number: %s\n", args[len(args)-1])
+			return 2
+		}
+		term = strings.Join(args[1:len(args)-1], " ")
+	}
+	result, err := searchArchive(archiveIndex, query, term, days)
+	if err != nil {
+		fmt.Fprintln(out, err.Error())
+		return 1
+	}
+	fmt.Fprintln(out, strings.TrimSpace(result))
+	return 0
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testIndex() *ArchiveIndex {
+	index := new(ArchiveIndex)
+	recent := getTime().Add(-24 * time.Hour).Format(time.RFC822)
+	old := getTime().AddDate(0, 0, -60).Format(time.RFC822)
+	index.Add("1", BidInvestigation{ItemName: "Cloth Cap", Ended: recent, WinningBid: 100, Bidders: []InvestigationBidder{
+		{Player: "Mortimus", Main: "Mortimus", BidApplied: 150, WonOrTied: true, Price: 100},
+		{Player: "Loser", Main: "Loser", BidApplied: 95},
+	}})
+	index.Add("2", BidInvestigation{ItemName: "Cloth Cap", Ended: old, WinningBid: 50, Bidders: []InvestigationBidder{
+		{Player: "Mortialt", Main: "Mortimus", BidApplied: 50, WonOrTied: true, Price: 50},
+	}})
+	index.Add("3", BidInvestigation{ItemName: "Cloth Cap", Ended: recent, Bidders: nil})
+	return index
+}
+
+func TestArchiveIndexQueries(t *testing.T) {
+	index := testIndex()
+	if got := len(index.Won("mortimus", getTime().AddDate(0, 0, -30))); got != 1 {
+		t.Errorf("Won(mortimus, 30 days) = %d auctions, want 1", got)
+	}
+	if got := len(index.Won("Mortimus", getTime().AddDate(0, 0, -90))); got != 2 {
+		t.Errorf("Won(Mortimus, 90 days) = %d auctions, want 2 counting the alt", got)
+	}
+	average, count := index.AveragePrice("cloth cap")
+	if average != 75 || count != 2 {
+		t.Errorf("AveragePrice(cloth cap) = %.1f, %d, want 75.0, 2", average, count)
+	}
+	lost := index.Lost("Cloth Cap")
+	if len(lost) != 1 || lost[0].Bidders[0].Player != "Loser" {
+		t.Errorf("Lost(Cloth Cap) = %+v, want Loser", lost)
+	}
+	if !index.Has("2") || index.Has("4") {
+		t.Errorf("Has() does not match the indexed auctions")
+	}
+}
+
+func TestArchiveIndexForMessage(t *testing.T) {
+	index := testIndex()
+	index.Add("20240101200000-1234", BidInvestigation{ItemName: "Cloth Cap", MessageID: "555", Ended: getTime().Format(time.RFC822)})
+	tests := []struct {
+		messageID string
+		want      string
+		found     bool
+	}{
+		{"555", "20240101200000-1234", true},
+		{"2", "2", true}, // named after its message before bid keys
+		{"", "", false},
+		{"404", "", false},
+	}
+	for _, tt := range tests {
+		got, found := index.ForMessage(tt.messageID)
+		if got != tt.want || found != tt.found {
+			t.Errorf("ForMessage(%q) = %q, %t, want %q, %t", tt.messageID, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSearchArchive(t *testing.T) {
+	index := testIndex()
+	got, err := searchArchive(index, "price", "Cloth Cap", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "average of 75.0") {
+		t.Errorf("searchArchive(price) = %q, want the average", got)
+	}
+	if _, err := searchArchive(index, "cheapest", "Cloth Cap", 0); err == nil {
+		t.Errorf("searchArchive() accepted an unknown query")
+	}
+}
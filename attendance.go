@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AttendanceGate makes bidders under an attendance threshold bid in a lower tier
+type AttendanceGate struct {
+	Tier    string  `comment:"Tier the gate applies to"`
+	Window  int     `comment:"Attendance window in days: 30, 60, 90 or 0 for all time"`
+	Minimum float64 `comment:"Minimum attendance in the window, in the units of the attendance column"`
+	DropTo  string  `comment:"Tier bidders under the minimum bid as"`
+}
+
+// validateAttendanceGates checks gates point at known tiers and windows
+func validateAttendanceGates(gates []AttendanceGate) error {
+	for i, gate := range gates {
+		if _, err := parseDKPRank(gate.Tier); err != nil {
+			return fmt.Errorf("attendance gate %d: %w", i+1, err)
+		}
+		if _, err := parseDKPRank(gate.DropTo); err != nil {
+			return fmt.Errorf("attendance gate %d: %w", i+1, err)
+		}
+		switch gate.Window {
+		case 0, 30, 60, 90:
+		default:
+			return fmt.Errorf("attendance gate %d: window must be 30, 60, 90 or 0, got %d", i+1, gate.Window)
+		}
+	}
+	return nil
+}
+
+// getAttendance returns the attendance of a character's main over a window in days, 0 is all time
+func getAttendance(player *DKPHolder, window int) float64 {
+	holder := player
+	if main, ok := Roster[getMain(&player.GuildMember)]; ok {
+		holder = main
+	}
+	switch window {
+	case 30:
+		return holder.Thirty
+	case 60:
+		return holder.Sixty
+	case 90:
+		return holder.Ninety
+	}
+	return holder.AllTime
+}
+
+func attendanceWindowName(window int) string {
+	if window == 0 {
+		return "all time"
+	}
+	return fmt.Sprintf("%d day", window)
+}
+
+// ApplyAttendanceGates drops bidders under their tier's attendance minimum to the gate's tier, each bidder is gated once
+func (b *OpenBid) ApplyAttendanceGates() {
+	for _, bidder := range b.Bidders {
+		if bidder.Rule != "" {
+			continue // already gated
+		}
+		tier := DKPRankToString(bidder.Player.DKPRank)
+		for _, gate := range configuration.Bids.AttendanceGates {
+			if !sameTier(gate.Tier, tier) {
+				continue
+			}
+			attendance := getAttendance(bidder.Player, gate.Window)
+			if attendance >= gate.Minimum {
+				break
+			}
+			dropTo, err := parseDKPRank(gate.DropTo)
+			if err != nil {
+				Err.Printf("Error applying attendance gate to %s: %s", bidder.Player.Name, err.Error())
+				break
+			}
+			bidder.Player.DKPRank = dropTo
+			bidder.Rule = fmt.Sprintf("%s under %.2f %s attendance (%.2f), bid as %s", tier, gate.Minimum, attendanceWindowName(gate.Window), attendance, DKPRankToString(dropTo))
+			break
+		}
+	}
+}
+
+// addRule records why a rule changed a bidder's result, after any rule already applied to them
+func (bidder *Bidder) addRule(rule string) {
+	if bidder.Rule != "" {
+		rule = bidder.Rule + "; " + rule
+	}
+	bidder.Rule = rule
+}
+
+// BreakTiesByAttendance settles ties on 30 day attendance, returning the players still tied that need to roll
+func (b *OpenBid) BreakTiesByAttendance(ties map[string]interface{}) map[string]interface{} {
+	if len(ties) == 0 {
+		return ties
+	}
+	var tied []*Bidder
+	var winners int
+	for _, bidder := range b.Bidders {
+		if !bidder.WonOrTied {
+			continue
+		}
+		if _, ok := ties[bidder.Player.Name]; ok {
+			tied = append(tied, bidder)
+		} else {
+			winners++
+		}
+	}
+	slots := b.Quantity - winners
+	if slots <= 0 || slots >= len(tied) {
+		return ties // everyone tied gets an item, nothing to break
+	}
+	sort.SliceStable(tied, func(i, j int) bool {
+		return getAttendance(tied[i].Player, 30) > getAttendance(tied[j].Player, 30)
+	})
+	cutoff := getAttendance(tied[slots-1].Player, 30)
+	remaining := make(map[string]interface{})
+	openSlots := slots
+	for _, bidder := range tied {
+		attendance := getAttendance(bidder.Player, 30)
+		switch {
+		case attendance > cutoff:
+			bidder.addRule(fmt.Sprintf("won tie on 30 day attendance (%.2f)", attendance))
+			openSlots--
+		case attendance == cutoff:
+			remaining[bidder.Player.Name] = nil
+		default:
+			bidder.WonOrTied = false
+			bidder.addRule(fmt.Sprintf("lost tie on 30 day attendance (%.2f)", attendance))
+		}
+	}
+	if len(remaining) <= openSlots {
+		for _, bidder := range tied {
+			if _, ok := remaining[bidder.Player.Name]; ok {
+				bidder.addRule(fmt.Sprintf("won tie on 30 day attendance (%.2f)", cutoff))
+			}
+		}
+		return make(map[string]interface{}) // attendance settled every tie
+	}
+	for _, bidder := range tied {
+		if _, ok := remaining[bidder.Player.Name]; ok {
+			bidder.addRule(fmt.Sprintf("tied on 30 day attendance (%.2f), rolling", cutoff))
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestAttendanceGate(t *testing.T) {
+	configuration.Bids.AttendanceGates = []AttendanceGate{{Tier: "Main", Window: 30, Minimum: 50, DropTo: "Recruit"}}
+	defer func() { configuration.Bids.AttendanceGates = nil }()
+	Roster["Gatelow"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Gatelow"}, DKPRank: MAIN, Thirty: 20}
+	Roster["Gatehigh"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Gatehigh"}, DKPRank: MAIN, Thirty: 80}
+	low := *Roster["Gatelow"]
+	high := *Roster["Gatehigh"]
+	bid := &OpenBid{Quantity: 1, Bidders: []*Bidder{{Player: &low}, {Player: &high}}}
+	bid.ApplyAttendanceGates()
+	if low.DKPRank != RECRUIT {
+		t.Errorf("Gatelow bid as %s; want Recruit", DKPRankToString(low.DKPRank))
+	}
+	if bid.Bidders[0].Rule == "" {
+		t.Errorf("Gatelow has no rule recorded")
+	}
+	if high.DKPRank != MAIN {
+		t.Errorf("Gatehigh bid as %s; want Main", DKPRankToString(high.DKPRank))
+	}
+}
+
+func TestBreakTiesByAttendance(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     float64
+		second    float64
+		wantRolls int
+		wantWin   string
+	}{
+		{"higher attendance wins", 80, 40, 0, "Tiefirst"},
+		{"lower attendance loses", 30, 90, 0, "Tiesecond"},
+		{"equal attendance rolls", 50, 50, 2, ""},
+	}
+	for _, tt := range tests {
+		Roster["Tiefirst"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Tiefirst"}, DKPRank: MAIN, Thirty: tt.first}
+		Roster["Tiesecond"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Tiesecond"}, DKPRank: MAIN, Thirty: tt.second}
+		bid := &OpenBid{Quantity: 1, Bidders: []*Bidder{
+			{Player: Roster["Tiefirst"], Bid: 100, WonOrTied: true},
+			{Player: Roster["Tiesecond"], Bid: 100, WonOrTied: true},
+		}}
+		ties := map[string]interface{}{"Tiefirst": nil, "Tiesecond": nil}
+		got := bid.BreakTiesByAttendance(ties)
+		if len(got) != tt.wantRolls {
+			t.Errorf("%s: BreakTiesByAttendance() left %d to roll; want %d", tt.name, len(got), tt.wantRolls)
+		}
+		if tt.wantWin != "" {
+			winners := bid.GetWinnerNames()
+			if len(winners) != 1 || winners[0] != tt.wantWin {
+				t.Errorf("%s: winners = %v; want [%s]", tt.name, winners, tt.wantWin)
+			}
+		}
+	}
+}
+
+func TestTieBreakKeepsGateRule(t *testing.T) {
+	configuration.Bids.AttendanceGates = []AttendanceGate{{Tier: "Main", Window: 30, Minimum: 50, DropTo: "Recruit"}}
+	defer func() { configuration.Bids.AttendanceGates = nil }()
+	Roster["Gatetiea"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Gatetiea"}, DKPRank: MAIN, Thirty: 40}
+	Roster["Gatetieb"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Gatetieb"}, DKPRank: MAIN, Thirty: 30}
+	first := *Roster["Gatetiea"]
+	second := *Roster["Gatetieb"]
+	bid := &OpenBid{Quantity: 1, Bidders: []*Bidder{
+		{Player: &first, Bid: 100, WonOrTied: true},
+		{Player: &second, Bid: 100, WonOrTied: true},
+	}}
+	bid.ApplyAttendanceGates()
+	bid.BreakTiesByAttendance(map[string]interface{}{"Gatetiea": nil, "Gatetieb": nil})
+	got := bid.Bidders[0].Rule
+	want := "Main under 50.00 30 day attendance (40.00), bid as Recruit; won tie on 30 day attendance (40.00)"
+	if got != want {
+		t.Errorf("Rule = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity of an audit finding, findings at or above Audit.InvestigateSeverity start an investigation
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "critical"
+}
+
+func parseSeverity(severity string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityCritical, errors.New("unknown audit severity: " + severity)
+}
+
+// AuditFinding is something an audit rule found on a closed bid
+type AuditFinding struct {
+	Rule     string `json:"Rule"`
+	Severity string `json:"Severity"`
+	Player   string `json:"Player,omitempty"`
+	Detail   string `json:"Detail"`
+}
+
+// AuditRule checks a closed bid for something an officer should look at
+type AuditRule interface {
+	Name() string
+	Severity() Severity // default severity, can be changed in Audit.Severities
+	Check(b *OpenBid) []AuditFinding
+}
+
+// auditCheck is an AuditRule made from a function
+type auditCheck struct {
+	name     string
+	severity Severity
+	check    func(b *OpenBid) []AuditFinding
+}
+
+func (c auditCheck) Name() string                    { return c.name }
+func (c auditCheck) Severity() Severity              { return c.severity }
+func (c auditCheck) Check(b *OpenBid) []AuditFinding { return c.check(b) }
+
+// AuditRules are run on every closed bid, in order
+var AuditRules []AuditRule
+
+func init() {
+	AuditRules = append(AuditRules,
+		auditCheck{"cannot-equip", SeverityCritical, auditCannotEquip},
+		auditCheck{"zero-dkp-win", SeverityCritical, auditZeroDKPWin},
+		auditCheck{"rot-spent", SeverityCritical, auditRotSpent},
+		auditCheck{"outside-window", SeverityWarning, auditOutsideWindow},
+		auditCheck{"not-in-raid", SeverityWarning, auditNotInRaid},
+		auditCheck{"alt-and-main", SeverityWarning, auditAltAndMain},
+		auditCheck{"duplicate-main", SeverityWarning, auditDuplicateMain},
+		auditCheck{"over-dkp", SeverityInfo, auditOverDKP},
+	)
+}
+
+// validateAudit checks the audit configuration before any bids are taken
+func validateAudit(audit Audit) error {
+	if audit.InvestigateSeverity != "" {
+		if _, err := parseSeverity(audit.InvestigateSeverity); err != nil {
+			return err
+		}
+	}
+	for _, severity := range audit.Severities {
+		if _, err := parseSeverity(severity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleSeverity is the configured severity of a rule, falling back to its default
+func ruleSeverity(rule AuditRule) Severity {
+	if override, ok := configuration.Audit.Severities[rule.Name()]; ok {
+		if severity, err := parseSeverity(override); err == nil {
+			return severity
+		}
+	}
+	return rule.Severity()
+}
+
+func ruleDisabled(rule AuditRule) bool {
+	for _, name := range configuration.Audit.Disabled {
+		if strings.EqualFold(name, rule.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit runs every enabled rule on a closed bid
+func (b *OpenBid) Audit() []AuditFinding {
+	findings := []AuditFinding{}
+	for _, rule := range AuditRules {
+		if ruleDisabled(rule) {
+			continue
+		}
+		severity := ruleSeverity(rule)
+		for _, finding := range rule.Check(b) {
+			finding.Rule = rule.Name()
+			finding.Severity = severity.String()
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// investigateSeverity is the lowest severity that starts an investigation, critical by default
+func investigateSeverity() Severity {
+	severity, err := parseSeverity(configuration.Audit.InvestigateSeverity)
+	if err != nil {
+		return SeverityCritical
+	}
+	return severity
+}
+
+func auditCannotEquip(b *OpenBid) []AuditFinding {
+	var findings []AuditFinding
+	if b.isFreeRoll() {
+		return nil // free rolls rank classes that cannot use the item last
+	}
+	for _, bidder := range b.Bidders {
+		if bidder.Bid > 0 && !canEquip(b.Item, bidder.Player.GuildMember) {
+			findings = append(findings, AuditFinding{Player: bidder.Player.Name, Detail: fmt.Sprintf("%s bid %d on an item their class cannot use", bidder.Player.Name, bidder.Bid)})
+		}
+	}
+	return findings
+}
+
+func auditZeroDKPWin(b *OpenBid) []AuditFinding {
+	if b.WinningBid == 0 && len(b.GetWinnerNames()) != 0 && !b.isFreeRoll() {
+		return []AuditFinding{{Detail: "Somehow we have a 0 dkp win"}}
+	}
+	return nil
+}
+
+func auditRotSpent(b *OpenBid) []AuditFinding {
+	if b.WinningBid > 0 && len(b.GetWinnerNames()) == 0 {
+		return []AuditFinding{{Detail: "Somehow we have a Rot spending DKP"}}
+	}
+	return nil
+}
+
+// auditOutsideWindow flags tells naming the item sent before bids opened or after they closed, from the tells kept for the investigation
+func auditOutsideWindow(b *OpenBid) []AuditFinding {
+	name := " " + normalizeItemName(b.Item.Name) + " "
+	var findings []AuditFinding
+	for _, log := range investigation.Messages {
+		if log.Channel != "tell" || isBetweenTime(log.T, b.Start, b.End) || !strings.Contains(" "+normalizeItemName(log.Msg)+" ", name) {
+			continue
+		}
+		findings = append(findings, AuditFinding{Player: log.Source, Detail: fmt.Sprintf("%s sent %q at %s, outside the bid window", log.Source, log.Msg, log.T.Format("15:04:05"))})
+	}
+	return findings
+}
+
+func auditNotInRaid(b *OpenBid) []AuditFinding {
+	members := currentRaidMembers()
+	if len(members) == 0 {
+		return nil // no raid dump to compare against
+	}
+	inRaid := make(map[string]bool)
+	for _, member := range members {
+		inRaid[member] = true
+	}
+	var findings []AuditFinding
+	for _, bidder := range b.Bidders {
+		if !inRaid[bidder.Player.Name] && bidder.Proxy == "" { // officers bid for players who cannot be online
+			findings = append(findings, AuditFinding{Player: bidder.Player.Name, Detail: fmt.Sprintf("%s is not in the latest raid dump", bidder.Player.Name)})
+		}
+	}
+	return findings
+}
+
+func auditAltAndMain(b *OpenBid) []AuditFinding {
+	var findings []AuditFinding
+	for _, bidder := range b.Bidders {
+		main := getMain(&bidder.Player.GuildMember)
+		if main != bidder.Player.Name && b.FindBid(main) >= 0 {
+			findings = append(findings, AuditFinding{Player: bidder.Player.Name, Detail: fmt.Sprintf("%s bid while their main %s also bid", bidder.Player.Name, main)})
+		}
+	}
+	return findings
+}
+
+func auditDuplicateMain(b *OpenBid) []AuditFinding {
+	characters := make(map[string][]string)
+	for _, bidder := range b.Bidders {
+		main := getMain(&bidder.Player.GuildMember)
+		characters[main] = append(characters[main], bidder.Player.Name)
+	}
+	var mains []string
+	for main := range characters {
+		mains = append(mains, main)
+	}
+	sort.Strings(mains)
+	var findings []AuditFinding
+	for _, main := range mains {
+		if len(characters[main]) < 2 || b.FindBid(main) >= 0 {
+			continue // alt-and-main covers the main bidding too
+		}
+		findings = append(findings, AuditFinding{Player: main, Detail: fmt.Sprintf("%s bid on more than one character: %s", main, strings.Join(characters[main], ", "))})
+	}
+	return findings
+}
+
+func auditOverDKP(b *OpenBid) []AuditFinding {
+	var findings []AuditFinding
+	for _, bidder := range b.Bidders {
+		if bidder.AttemptedBid > bidder.Player.DKP {
+			findings = append(findings, AuditFinding{Player: bidder.Player.Name, Detail: fmt.Sprintf("%s bid %d with only %d DKP", bidder.Player.Name, bidder.AttemptedBid, bidder.Player.DKP)})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func auditHits(findings []AuditFinding, rule string) int {
+	var hits int
+	for _, finding := range findings {
+		if finding.Rule == rule {
+			hits++
+		}
+	}
+	return hits
+}
+
+func TestAuditRules(t *testing.T) {
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	Roster["Auditmain"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Auditmain"}, DKP: 50, DKPRank: MAIN}
+	Roster["Auditalt"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Auditalt", Alt: true, PublicNote: "Auditmain's Alt"}, DKP: 50, DKPRank: ALT}
+	Roster["Auditaltb"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Auditaltb", Alt: true, PublicNote: "Auditmain's Alt"}, DKP: 50, DKPRank: ALT}
+	start := time.Now()
+	bid := &OpenBid{Item: item, Quantity: 1, Start: start, End: start.Add(time.Minute)}
+	bid.AddBid(*Roster["Auditmain"], 100, everquest.EqLog{T: start.Add(time.Second)})
+	bid.AddBid(*Roster["Auditalt"], 20, everquest.EqLog{T: start.Add(2 * time.Second)})
+	oldMessages := investigation.Messages
+	investigation.Messages = []everquest.EqLog{{Channel: "tell", Source: "Auditalt", Msg: "Cloth Cap 30", T: start.Add(2 * time.Minute)}}
+	defer func() { investigation.Messages = oldMessages }()
+	findings := bid.Audit()
+	tests := []struct {
+		rule string
+		want int
+	}{
+		{"over-dkp", 1},
+		{"outside-window", 1},
+		{"alt-and-main", 1},
+		{"duplicate-main", 0},
+	}
+	for _, tt := range tests {
+		if got := auditHits(findings, tt.rule); got != tt.want {
+			t.Errorf("Audit() %s hits = %d, want %d", tt.rule, got, tt.want)
+		}
+	}
+
+	dupe := &OpenBid{Item: item, Quantity: 1, Start: start, End: start.Add(time.Minute)}
+	dupe.AddBid(*Roster["Auditalt"], 20, everquest.EqLog{T: start.Add(time.Second)})
+	dupe.AddBid(*Roster["Auditaltb"], 20, everquest.EqLog{T: start.Add(time.Second)})
+	if got := auditHits(dupe.Audit(), "duplicate-main"); got != 1 {
+		t.Errorf("Audit() duplicate-main hits = %d, want 1", got)
+	}
+}
+
+func TestAuditSeverityConfig(t *testing.T) {
+	rule := auditCheck{"over-dkp", SeverityInfo, auditOverDKP}
+	configuration.Audit.Severities = map[string]string{"over-dkp": "critical"}
+	configuration.Audit.Disabled = []string{"over-dkp"}
+	defer func() {
+		configuration.Audit.Severities = nil
+		configuration.Audit.Disabled = nil
+	}()
+	if got := ruleSeverity(rule); got != SeverityCritical {
+		t.Errorf("ruleSeverity() = %s, want critical", got)
+	}
+	if !ruleDisabled(rule) {
+		t.Errorf("ruleDisabled() = false, want true")
+	}
+	if err := validateAudit(Audit{Severities: map[string]string{"over-dkp": "loud"}}); err == nil {
+		t.Errorf("validateAudit() accepted an unknown severity")
+	}
+}
+
+func TestAuditOutsideWindowTells(t *testing.T) {
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	start := time.Date(2021, 4, 17, 21, 0, 0, 600000000, time.UTC) // bid windows have sub-second times, logs do not
+	bid := &OpenBid{Item: item, Quantity: 1, Start: start, End: start.Add(2 * time.Minute)}
+	oldMessages := investigation.Messages
+	investigation.Messages = []everquest.EqLog{
+		{Channel: "tell", Source: "Windowfirst", Msg: "Cloth Cap 50", T: start.Truncate(time.Second)},
+		{Channel: "tell", Source: "Windowlate", Msg: "cloth cap 60", T: start.Add(3 * time.Minute)},
+		{Channel: "tell", Source: "Windowlate", Msg: "Spiked Seahorse Hide Belt 10", T: start.Add(3 * time.Minute)},
+		{Channel: "guild", Source: "You", Msg: "Cloth Cap bids to Bids, CLOSED", T: start.Add(3 * time.Minute)},
+	}
+	defer func() { investigation.Messages = oldMessages }()
+	findings := auditOutsideWindow(bid)
+	if len(findings) != 1 || findings[0].Player != "Windowlate" {
+		t.Errorf("auditOutsideWindow() = %+v; want one finding for the tell sent after close", findings)
+	}
+}
+
+func TestAuditNotInRaidSkipsProxyBids(t *testing.T) {
+	raid := testRaidPlugin(t)
+	oldRaid := raid.LastRaid
+	raid.LastRaid = everquest.Raid{Members: []everquest.RaidMember{{Player: "Auditmain"}}}
+	defer func() { raid.LastRaid = oldRaid }()
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	Roster["Auditoffline"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Auditoffline"}, DKP: 50, DKPRank: MAIN}
+	Roster["Auditproxied"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Auditproxied"}, DKP: 50, DKPRank: MAIN}
+	start := time.Now()
+	bid := &OpenBid{Item: item, Quantity: 1, Start: start, End: start.Add(time.Minute)}
+	bid.AddBid(*Roster["Auditoffline"], 10, everquest.EqLog{T: start.Add(time.Second)})
+	bid.AddBid(*Roster["Auditproxied"], 10, everquest.EqLog{T: start.Add(time.Second)})
+	bid.Bidders[bid.FindBid("Auditproxied")].Proxy = "Auditmain"
+	findings := auditNotInRaid(bid)
+	if len(findings) != 1 || findings[0].Player != "Auditoffline" {
+		t.Errorf("auditNotInRaid() = %v, want only Auditoffline", findings)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// bidJournalVersion is bumped whenever the journal's shape changes, journals without one are the old map of open bids
+const bidJournalVersion = 1
+
+// bidJournal is what is saved to disk, open bids and closed bids still waiting on a roll off
+type bidJournal struct {
+	Version  int
+	Open     map[int]*OpenBid
+	RollOffs []*RollOff
+}
+
+// recoveredBids are bids restored from the journal, announced once discord is connected
+var recoveredBids []*OpenBid
+
+// recoveredRollOffs are roll offs restored from the journal, announced once discord is connected
+var recoveredRollOffs []*RollOff
+
+// saveBids journals all open bids and pending roll offs to disk so they survive a crash or reboot
+func (p *BidPlugin) saveBids() {
+	if configuration.Bids.JournalPath == "" {
+		return
+	}
+	file, err := json.MarshalIndent(bidJournal{Version: bidJournalVersion, Open: p.Bids, RollOffs: rollOffs}, "", " ")
+	if err != nil {
+		Err.Printf("Error converting open bids to JSON: %s", err.Error())
+		return
+	}
+	tmp := configuration.Bids.JournalPath + ".tmp"
+	err = ioutil.WriteFile(tmp, file, 0644)
+	if err != nil {
+		Err.Printf("Error writing open bids journal: %s", err.Error())
+		return
+	}
+	err = os.Rename(tmp, configuration.Bids.JournalPath) // replace in one step so a crash never leaves half a journal
+	if err != nil {
+		Err.Printf("Error replacing open bids journal: %s", err.Error())
+	}
+}
+
+// loadBids restores open bids and pending roll offs from the journal
+func (p *BidPlugin) loadBids() error {
+	if configuration.Bids.JournalPath == "" {
+		return nil
+	}
+	file, err := ioutil.ReadFile(configuration.Bids.JournalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // nothing was open
+		}
+		return err
+	}
+	var journal bidJournal
+	err = json.Unmarshal(file, &journal)
+	if err != nil {
+		return err
+	}
+	switch {
+	case journal.Version == 0: // written before roll offs were journaled, only holds open bids by item id
+		journal = bidJournal{}
+		err = json.Unmarshal(file, &journal.Open)
+		if err != nil {
+			return err
+		}
+	case journal.Version > bidJournalVersion:
+		return fmt.Errorf("bid journal version %d is newer than this bot understands (%d)", journal.Version, bidJournalVersion)
+	}
+	for id, bid := range journal.Open {
+		if _, ok := p.Bids[id]; ok {
+			continue
+		}
+		bid.ensureKey()
+		p.Bids[id] = bid
+		recoveredBids = append(recoveredBids, bid)
+		Info.Printf("Recovered bids on %s (x%d) with %d bidders", bid.Item.Name, bid.Quantity, len(bid.Bidders))
+	}
+	for _, r := range journal.RollOffs {
+		if r.Bid == nil {
+			continue
+		}
+		if r.Rolls == nil {
+			r.Rolls = make(map[string]int)
+		}
+		r.Bid.ensureKey()
+		r.Deadline = getTime().Add(rollOffTimeout()) // rolls made while the bot was down were missed, give everyone time again
+		for _, player := range r.Players {
+			if _, ok := r.Rolls[player]; !ok {
+				needsRolled = append(needsRolled, player)
+			}
+		}
+		rollOffs = append(rollOffs, r)
+		recoveredRollOffs = append(recoveredRollOffs, r)
+		Info.Printf("Recovered roll off for %s (x%d) between %s", r.Bid.Item.Name, r.Bid.Quantity, strings.Join(r.Players, ", "))
+	}
+	return nil
+}
+
+// ensureKey gives a bid from a journal written before bid keys the key its archive and ledger entries used, the message id
+func (b *OpenBid) ensureKey() {
+	if b.Key == "" {
+		b.Key = b.MessageID
+	}
+	if b.Key == "" {
+		b.Key = newBidKey(b.Item.ID)
+	}
+}
+
+// announceRecoveredBids lets the loot channel know bids survived a restart, needs to run AFTER discord is opened
+func announceRecoveredBids() {
+	for _, bid := range recoveredBids {
+		fmt.Printf("Recovered bids on %s (x%d) with %d bidders\n", bid.Item.Name, bid.Quantity, len(bid.Bidders))
+		err := updateMessage(configuration.Discord.LootChannelID, bid.MessageID, fmt.Sprintf("> Bids recovered after a restart, %d bid(s) restored", len(bid.Bidders)))
+		if err != nil {
+			Err.Println(err)
+		}
+	}
+	recoveredBids = nil
+	for _, r := range recoveredRollOffs {
+		fmt.Printf("Recovered roll off for %s (x%d) between %s\n", r.Bid.Item.Name, r.Bid.Quantity, strings.Join(r.Players, ", "))
+		var waiting []string
+		for _, player := range r.Players {
+			if _, ok := r.Rolls[player]; !ok {
+				waiting = append(waiting, player)
+			}
+		}
+		err := updateMessage(configuration.Discord.LootChannelID, r.Bid.MessageID, fmt.Sprintf("```diff\n- Roll off recovered after a restart, /rand 1000 still needed for %s from %s```", r.Bid.Item.Name, strings.Join(waiting, ", ")))
+		if err != nil {
+			Err.Println(err)
+		}
+	}
+	recoveredRollOffs = nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestBidJournalRestore(t *testing.T) {
+	configuration.Bids.JournalPath = filepath.Join(t.TempDir(), "openbids.json")
+	defer func() { configuration.Bids.JournalPath = "" }()
+	plug := new(BidPlugin)
+	plug.Bids = make(map[int]*OpenBid)
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	plug.Bids[id] = &OpenBid{
+		Item:      item,
+		Quantity:  2,
+		Duration:  2 * time.Minute,
+		Start:     time.Now(),
+		End:       time.Now().Add(2 * time.Minute),
+		Bidders:   []*Bidder{},
+		MessageID: "123456789",
+	}
+	tell := everquest.EqLog{Channel: "tell", Source: "Mortimus", Msg: "Cloth Cap 50", T: time.Now()}
+	plug.Bids[id].AddBid(DKPHolder{GuildMember: everquest.GuildMember{Name: "Mortimus", Class: "Necromancer"}}, 50, tell)
+	plug.saveBids()
+
+	restored := new(BidPlugin)
+	restored.Bids = make(map[int]*OpenBid)
+	err := restored.loadBids()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveredBids = nil
+	if _, ok := restored.Bids[id]; !ok {
+		t.Fatalf("bid on %s was not restored", item.Name)
+	}
+	got := restored.Bids[id].Quantity
+	want := 2
+	if got != want {
+		t.Errorf("Got %d, want %d", got, want)
+	}
+	pos := restored.Bids[id].FindBid("Mortimus")
+	if pos < 0 {
+		t.Fatalf("Got %d, want %s", pos, "positive number")
+	}
+	got2 := restored.Bids[id].Bidders[pos].Message.Msg
+	want2 := tell.Msg
+	if got2 != want2 {
+		t.Errorf("Got %s, want %s", got2, want2)
+	}
+	got3 := restored.Bids[id].MessageID
+	want3 := "123456789"
+	if got3 != want3 {
+		t.Errorf("Got %s, want %s", got3, want3)
+	}
+	got4 := restored.Bids[id].Key // saved without a key, keeps using the message id for its archive and ledger rows
+	if got4 != want3 {
+		t.Errorf("Got key %s, want %s", got4, want3)
+	}
+}
+
+func TestBidJournalRestoresRollOff(t *testing.T) {
+	configuration.Bids.JournalPath = filepath.Join(t.TempDir(), "openbids.json")
+	defer func() { configuration.Bids.JournalPath = "" }()
+	bid := rollOffTestBid("Journala", "Journalb")
+	rollOffs[0].Rolls["Journala"] = 300
+	plug := new(BidPlugin)
+	plug.Bids = make(map[int]*OpenBid)
+	plug.saveBids()
+	rollOffs = nil
+	needsRolled = []string{}
+
+	restored := new(BidPlugin)
+	restored.Bids = make(map[int]*OpenBid)
+	err := restored.loadBids()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveredRollOffs = nil
+	defer func() {
+		rollOffs = nil
+		needsRolled = []string{}
+	}()
+	if len(rollOffs) != 1 {
+		t.Fatalf("Got %d roll offs, want 1", len(rollOffs))
+	}
+	got := rollOffs[0].Bid.Item.Name
+	want := bid.Item.Name
+	if got != want {
+		t.Errorf("Got %s, want %s", got, want)
+	}
+	got2 := rollOffs[0].Rolls["Journala"]
+	want2 := 300
+	if got2 != want2 {
+		t.Errorf("Got %d, want %d", got2, want2)
+	}
+	if len(needsRolled) != 1 || needsRolled[0] != "Journalb" {
+		t.Errorf("Got %v, want [Journalb]", needsRolled)
+	}
+}
+
+func TestBidJournalRestoresOldShape(t *testing.T) {
+	configuration.Bids.JournalPath = filepath.Join(t.TempDir(), "openbids.json")
+	defer func() { configuration.Bids.JournalPath = "" }()
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	old, err := json.Marshal(map[int]*OpenBid{id: {Item: item, Quantity: 1, Bidders: []*Bidder{}, MessageID: "987654321"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(configuration.Bids.JournalPath, old, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := new(BidPlugin)
+	restored.Bids = make(map[int]*OpenBid)
+	err = restored.loadBids()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveredBids = nil
+	if _, ok := restored.Bids[id]; !ok {
+		t.Fatalf("bid on %s was not restored from the old journal", item.Name)
+	}
+	got := restored.Bids[id].MessageID
+	want := "987654321"
+	if got != want {
+		t.Errorf("Got %s, want %s", got, want)
+	}
+}
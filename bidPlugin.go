@@ -43,9 +43,14 @@ type OpenBid struct {
 	Bidders              []*Bidder
 	Zone                 string
 	MessageID            string
+	Key                  string // made by the bot when bids open, names the archive and ledger entries even if the discord message failed
 	SecondMainBidsAsMain bool
 	SecondMainMaxBid     int
 	WinningBid           int
+	Warned               bool
+	ItemRule             *ItemRule
+	Cancelled            []*Bidder // bidders that cancelled, kept for their timeline
+	Findings             []AuditFinding
 }
 
 type Bidder struct {
@@ -54,6 +59,11 @@ type Bidder struct {
 	AttemptedBid int
 	Bid          int
 	WonOrTied    bool
+	Rule         string // attendance and tie rules applied to this bidder, in order
+	Price        int    // what the bidder pays if they won
+	Tell         string // whole tell when it held bids on several items
+	Proxy        string // officer that placed the bid for this player, if any
+	Events       []BidEvent
 }
 
 // DKP Ranks
@@ -81,6 +91,9 @@ type DKPHolder struct {
 var Roster map[string]*DKPHolder
 var updateDKP bool
 
+// rosterDKPLoaded is set once the roster has DKP from the ledger, before that every balance reads 0
+var rosterDKPLoaded bool
+
 func init() {
 	plug := new(BidPlugin)
 	plug.Name = "Bidding detection"
@@ -96,23 +109,29 @@ func init() {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.Bids = make(map[int]*OpenBid)
 	Roster = make(map[string]*DKPHolder)
-	path, err := everquest.GetRecentRosterDump(configuration.Everquest.BaseFolder, configuration.Everquest.GuildName)
-	if err != nil {
-		fmt.Printf("Error finding roster dump: %s", err.Error())
-	} else {
-		guild := new(everquest.Guild)
-		fileLog := log.New(os.Stdout, "[WARN] ", log.Lshortfile|log.Ldate|log.Ltime|log.LUTC|log.Lmsgprefix)
-		fullpath := configuration.Everquest.BaseFolder + "/" + path
-		apiUploadGuildRoster(fullpath)
-		err := guild.LoadFromPath(fullpath, fileLog)
+	if !searchOnly { // the search subcommand runs offline, it needs neither open bids nor the roster
+		err := plug.loadBids()
+		if err != nil {
+			fmt.Printf("Error loading open bids journal: %s", err.Error())
+		}
+		path, err := everquest.GetRecentRosterDump(configuration.Everquest.BaseFolder, configuration.Everquest.GuildName)
 		if err != nil {
-			fmt.Printf("Error loading roster dump: %s", err.Error())
+			fmt.Printf("Error finding roster dump: %s", err.Error())
 		} else {
-			loadGuildRoster(guild)
+			guild := new(everquest.Guild)
+			fileLog := log.New(os.Stdout, "[WARN] ", log.Lshortfile|log.Ldate|log.Ltime|log.LUTC|log.Lmsgprefix)
+			fullpath := configuration.Everquest.BaseFolder + "/" + path
+			apiUploadGuildRoster(fullpath)
+			err = guild.LoadFromPath(fullpath, fileLog)
+			if err != nil {
+				fmt.Printf("Error loading roster dump: %s", err.Error())
+			} else {
+				loadGuildRoster(guild)
+			}
 		}
 	}
 
-	Handlers = append(Handlers, plug)
+	registerPlugin("bids", plug)
 	updateDKP = true
 }
 
@@ -200,7 +219,7 @@ func fixOutrankingSecondMains() {
 		if member.DKPRank == SECONDMAIN {
 			main := getMain(&Roster[member.Name].GuildMember)
 			mainRank := &Roster[main].GuildMember
-			if getDKPRank(mainRank) < SECONDMAIN {
+			if tierPriority(getDKPRank(mainRank)) < tierPriority(SECONDMAIN) {
 				Roster[member.Name].Rank = Roster[main].Rank
 				Roster[member.Name].PublicNote = ""
 			}
@@ -218,30 +237,26 @@ func updateRosterDKP() {
 		member.Ninety = 0
 		member.AllTime = 0
 	}
-	// TODO: Update DKP and Attendance
-	// Info.Printf("Getting Attendance from Google Sheets\n")
-	spreadsheetID := configuration.Sheets.RawSheetURL
-	readRange := configuration.Sheets.RawSheetName
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
+	rows, err := dkpStore.LoadLedger()
 	if err != nil {
 		Err.Printf("Unable to retrieve data from sheet: %v", err)
 		DiscordF(configuration.Discord.InvestigationChannelID, "Unable to read data from the DKP sheet, cannot calculate winners! - %s\n", err)
-		// return errors.New("Unable to retrieve data from sheet: " + err.Error())
+		return
 	}
 
-	if len(resp.Values) == 0 {
-		Err.Printf("Cannot read dkp sheet: %v", resp)
+	if len(rows) == 0 {
+		Err.Printf("Cannot read dkp sheet: no rows")
 	} else {
-		for i, row := range resp.Values {
+		for i, row := range rows {
 			if i == 0 {
 				continue // skip the header
 			}
-			name := fmt.Sprintf("%s", row[configuration.Sheets.RawSheetPlayerCol])
+			name := cell(row, configuration.Sheets.RawSheetPlayerCol)
 			name = strings.TrimSpace(name)
 			name = strings.Title(name)
 			if name != "" {
 				// 06/27/20
-				dateString := fmt.Sprintf("%s", row[configuration.Sheets.RawSheetDateCol])
+				dateString := cell(row, configuration.Sheets.RawSheetDateCol)
 				if dateString == "" { // just to lower the logging
 					dateString = "1/2/2006" // set to some old date so it's not counted towards current attendance
 				}
@@ -251,7 +266,7 @@ func updateRosterDKP() {
 					// continue
 					date = time.Date(2006, 1, 1, 0, 0, 0, 0, time.Local) // set to some old date so it's not counted towards current attendance
 				}
-				dkpString := fmt.Sprintf("%s", row[configuration.Sheets.RawSheetDKPCol])
+				dkpString := cell(row, configuration.Sheets.RawSheetDKPCol)
 				if dkpString == "" { // just to lower the logging
 					dkpString = "0"
 				}
@@ -261,7 +276,7 @@ func updateRosterDKP() {
 					// continue
 					dkpPoints = 0
 				}
-				attString := fmt.Sprintf("%s", row[configuration.Sheets.RawSheetAttendanceCol])
+				attString := cell(row, configuration.Sheets.RawSheetAttendanceCol)
 				if attString == "" { // just to lower the logging
 					attString = "0.0"
 				}
@@ -276,6 +291,7 @@ func updateRosterDKP() {
 		}
 	}
 	updateAltDKP()
+	rosterDKPLoaded = true
 }
 
 // type RawDKP struct {
@@ -300,13 +316,57 @@ func TimeStamp() string {
 	return strings.Replace(ts, ":", "", -1) // get rid of offensive colons
 }
 
-func exportSpentDKP(winners []string, winningBid int, itemname string) {
-	var csvDATA string
-	if len(winners) < 1 {
+const spentKeyPrefix = "BIDBOT_AUTO_FILL"
+const revertKeyPrefix = "BIDBOT_REVERT"
+
+// spentKeyMatch finds the ledger key named in a spent dkp summary message
+var spentKeyMatch = regexp.MustCompile(spentKeyPrefix + ` ([^\s)]+)`)
+
+func exportSpentDKP(charges []Charge, itemname string, key string) {
+	if len(charges) < 1 {
 		return
 	}
-	for _, winner := range winners {
-		// tempDATA := csvDATA
+	rows := spentDKPRows(charges, itemname, key)
+	if len(rows) == 0 {
+		return
+	}
+	itemname = cleanItemName(itemname)
+	if !configuration.Bids.WriteSpentDKP {
+		discordLong(configuration.Discord.InvestigationChannelID, fmt.Sprintf("[%s] DKP Entry for %s\n```\n%v\n```", getPlayerName(configuration.Everquest.LogPath), itemname, rowsToCSV(rows)))
+		return
+	}
+	charged, err := ledgerHasKey(spentKeyPrefix + " " + key)
+	if err != nil {
+		Err.Printf("Unable to check ledger for %s: %s", key, err.Error())
+		discordLong(configuration.Discord.InvestigationChannelID, fmt.Sprintf("[%s] Unable to verify the ledger, DKP was NOT written for %s - %s\n```\n%v\n```", getPlayerName(configuration.Everquest.LogPath), itemname, err, rowsToCSV(rows)))
+		return
+	}
+	if charged {
+		Info.Printf("DKP for %s (%s) was already written to the ledger", itemname, key)
+		return
+	}
+	err = dkpStore.AppendSpent(rows)
+	if err != nil {
+		Err.Printf("Unable to write spent dkp: %s", err.Error())
+		discordLong(configuration.Discord.InvestigationChannelID, fmt.Sprintf("[%s] Unable to write DKP for %s, please enter manually - %s\n```\n%v\n```", getPlayerName(configuration.Everquest.LogPath), itemname, err, rowsToCSV(rows)))
+		return
+	}
+	// the summary only names the ledger key so it stays short, the rows follow in as many messages as they need
+	summaryID := DiscordMessageF(configuration.Discord.InvestigationChannelID, "[%s] DKP written to ledger for %s (%s %s), react with %s to revert", getPlayerName(configuration.Everquest.LogPath), itemname, spentKeyPrefix, key, configuration.Discord.RevertSpentEmoji)
+	if configuration.Discord.UseDiscord && summaryID != "" {
+		err = discordReaction(configuration.Discord.InvestigationChannelID, summaryID, configuration.Discord.RevertSpentEmoji)
+		if err != nil {
+			Err.Printf("Error adding revert reaction: %s", err.Error())
+		}
+	}
+	discordLong(configuration.Discord.InvestigationChannelID, "```\n"+rowsToCSV(rows)+"```")
+}
+
+// spentDKPRows builds a ledger row for each charge, the raid column holds the bid key so the entry can be found again
+func spentDKPRows(charges []Charge, itemname string, key string) [][]string {
+	var rows [][]string
+	for _, charge := range charges {
+		winner := charge.Name
 		if _, ok := Roster[winner]; !ok { // Verify the member is in the map
 			continue
 		}
@@ -314,56 +374,138 @@ func exportSpentDKP(winners []string, winningBid int, itemname string) {
 		day := time.Now().Format("Mon")
 		date := time.Now().Format("1/2/2006")
 		smallDate := time.Now().Format("01/02")
-		points := fmt.Sprintf("-%d", winningBid)
+		points := strconv.Itoa(-charge.Cost)
+		entryType := charge.Type
+		if entryType == "" {
+			entryType = "Spent"
+		}
 		var alt string
 		if main != winner {
 			alt = winner
 		}
-		itemname = strings.ReplaceAll(itemname, "'", "")
-		itemname = strings.ReplaceAll(itemname, "`", "")
-		row := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s\n", main, day, date, smallDate+" BIDBOT_AUTO_FILL", "Spent", itemname, points, alt) // Name, Day, Date, Raid, Type, Reason, Points, AltOrSecondMain
-		csvDATA += row
+		raid := smallDate + " " + spentKeyPrefix
+		if key != "" {
+			raid += " " + key
+		}
+		rows = append(rows, []string{main, day, date, raid, entryType, cleanItemName(itemname), points, alt}) // Name, Day, Date, Raid, Type, Reason, Points, AltOrSecondMain
+	}
+	return rows
+}
+
+func cleanItemName(itemname string) string {
+	itemname = strings.ReplaceAll(itemname, "'", "")
+	itemname = strings.ReplaceAll(itemname, "`", "")
+	return itemname
+}
+
+func rowsToCSV(rows [][]string) string {
+	var csvDATA string
+	for _, row := range rows {
+		csvDATA += strings.Join(row, ",") + "\n"
+	}
+	return csvDATA
+}
+
+// ledgerHasKey checks if any ledger row was written with the key
+func ledgerHasKey(key string) (bool, error) {
+	rows, err := dkpStore.LoadLedger()
+	if err != nil {
+		return false, err
+	}
+	for _, row := range rows {
+		for _, record := range row {
+			if strings.HasSuffix(strings.TrimSpace(record), key) {
+				return true, nil
+			}
+		}
 	}
-	if csvDATA == "" {
+	return false, nil
+}
+
+// revertSpentDKP refunds the ledger entries named in a spent dkp summary message
+func revertSpentDKP(summaryID string) {
+	if !configuration.Discord.UseDiscord {
+		return
+	}
+	msg, err := discord.ChannelMessage(configuration.Discord.InvestigationChannelID, summaryID)
+	if err != nil {
+		Err.Printf("Error finding spent dkp message %s: %s", summaryID, err.Error())
+		return
+	}
+	if msg.Author == nil || msg.Author.ID != discord.State.User.ID || !strings.Contains(msg.Content, "DKP written to ledger") {
+		return // only entries the bot wrote can be reverted
+	}
+	match := spentKeyMatch.FindStringSubmatch(msg.Content)
+	if match == nil {
+		Err.Printf("Spent dkp message %s has no ledger key", summaryID)
 		return
 	}
-	DiscordF(configuration.Discord.InvestigationChannelID, "[%s] DKP Entry for %s\n```\n%v\n```", getPlayerName(configuration.Everquest.LogPath), itemname, csvDATA)
+	refunds, err := revertSpentKey(match[1])
+	if err != nil {
+		Err.Printf("Unable to revert spent dkp for %s: %s", match[1], err.Error())
+		if len(refunds) > 0 {
+			discordLong(configuration.Discord.InvestigationChannelID, fmt.Sprintf("Unable to revert DKP, please fix manually - %s\n```\n%v\n```", err, rowsToCSV(refunds)))
+		}
+		return
+	}
+	if len(refunds) == 0 {
+		return
+	}
+	discordLong(configuration.Discord.InvestigationChannelID, fmt.Sprintf("[%s] DKP reverted\n```\n%v\n```", getPlayerName(configuration.Everquest.LogPath), rowsToCSV(refunds)))
+}
+
+// revertSpentKey writes a refund for every ledger row spent under the key, returning the refunds
+func revertSpentKey(messageID string) ([][]string, error) {
+	reverted, err := ledgerHasKey(revertKeyPrefix + " " + messageID)
+	if err != nil {
+		return nil, err
+	}
+	if reverted {
+		Info.Printf("DKP for %s was already reverted", messageID)
+		return nil, nil
+	}
+	rows, err := dkpStore.LoadLedger()
+	if err != nil {
+		return nil, err
+	}
+	var refunds [][]string
+	for _, row := range rows { // rows are read back in the layout spentDKPRows wrote them
+		if len(row) < 7 || !strings.HasSuffix(strings.TrimSpace(row[3]), spentKeyPrefix+" "+messageID) {
+			continue
+		}
+		points, err := strconv.Atoi(row[6])
+		if err != nil {
+			Err.Printf("Ledger entry for %s has bad points %s", messageID, row[6])
+			continue
+		}
+		refunds = append(refunds, []string{row[0], time.Now().Format("Mon"), time.Now().Format("1/2/2006"), time.Now().Format("01/02") + " " + revertKeyPrefix + " " + messageID, row[4], "REVERTED " + row[5], strconv.Itoa(-points), cell(row, 7)})
+	}
+	if len(refunds) == 0 {
+		Err.Printf("Ledger has no entries for %s to revert", messageID)
+		return nil, nil
+	}
+	return refunds, dkpStore.AppendSpent(refunds)
 }
 
 func exportDKP(path string) {
-	// TODO: Update DKP and Attendance
-	// Info.Printf("Getting Attendance from Google Sheets\n")
-	spreadsheetID := configuration.Sheets.RawSheetURL
-	readRange := configuration.Sheets.RawSheetName
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
+	rows, err := dkpStore.LoadLedger()
 	if err != nil {
 		Err.Printf("Unable to retrieve data from sheet: %v", err)
 		DiscordF(configuration.Discord.InvestigationChannelID, "Unable to read data from the DKP sheet, cannot perform backup! - %s\n", err)
-		// return errors.New("Unable to retrieve data from sheet: " + err.Error())
+		return
 	}
 
-	if len(resp.Values) == 0 {
-		Err.Printf("Cannot read dkp sheet: %v", resp)
+	if len(rows) == 0 {
+		Err.Printf("Cannot read dkp sheet: no rows")
 	} else {
 		csvFile, err := os.Create(path)
 		if err != nil {
 			DiscordF(configuration.Discord.InvestigationChannelID, "Unable to write DKP backup! - %s\n", err)
-			// log.Printf("failed creating file: %s", err)
+			return
 		}
 		fmt.Printf("Exporting DKP to %s\n", path)
 		csvwriter := csv.NewWriter(csvFile)
-		for _, row := range resp.Values {
-			// if i == 0 {
-			// 	continue // skip the header
-			// }
-			var rec []string
-			for _, record := range row {
-				rec = append(rec, fmt.Sprintf("%s", record))
-			}
-			_ = csvwriter.Write(rec)
-			csvwriter.Flush()
-		}
-		csvwriter.Flush()
+		_ = csvwriter.WriteAll(rows)
 		csvFile.Close()
 		fmt.Printf("Exporting DKP to %s COMPLETE\n", path)
 	}
@@ -389,7 +531,7 @@ func addDKPAttendance(name string, date time.Time, dkp int, attendance float64)
 
 func updateAltDKP() {
 	for _, member := range Roster {
-		if member.GuildMember.Alt {
+		if isAlt(&member.GuildMember) {
 			if _, ok := Roster[member.GuildMember.Name]; ok {
 				// fmt.Printf("Updating %s with %s' DKP", name, member.GuildMember.Name)
 				Roster[member.GuildMember.Name].DKP = Roster[getMain(&member.GuildMember)].DKP
@@ -403,31 +545,16 @@ func updateAltDKP() {
 }
 
 func getDKPRank(member *everquest.GuildMember) DKPRank {
-	if member.Rank == "Inactive" {
-		return INACTIVE
-	}
-	if !member.Alt && member.HasRank([]string{"Guild Leader", "Veteran Raider", "Officer", "Raider"}) {
-		return MAIN
-	}
-	if member.Alt && strings.Contains(member.PublicNote, "nd Main") || member.Alt && strings.Contains(member.PublicNote, "nd main") {
-		// Check if their main has a lower rank than SECONDMAIN
-		return SECONDMAIN
-	}
-	if member.Rank == "Recruit" {
-		return RECRUIT
-	}
-	if member.Alt {
-		return ALT
-	}
-	if member.Rank == "Member" { // TODO: fix for alts - this actually is fine
-		return SOCIAL
+	for _, rule := range rankPolicy {
+		if rule.matches(member) {
+			return rule.tier
+		}
 	}
-
 	return INACTIVE
 }
 
 func getMain(member *everquest.GuildMember) string {
-	if member.Alt {
+	if isAlt(member) {
 		if strings.Contains(member.PublicNote, "'") { // Mortimus's 2nd Main Mortimus's Alt
 			s := strings.Split(member.PublicNote, "'")
 			if _, ok := Roster[s[0]]; ok {
@@ -460,11 +587,15 @@ func DKPRankToString(rank DKPRank) string {
 	case ALT:
 		return "Alt"
 	}
+	if custom := customTierNames(); rank > MAIN && int(rank-MAIN) <= len(custom) {
+		return custom[rank-MAIN-1]
+	}
 	return "Unknown"
 }
 
-// Handle for BidPlugin sends a message if it detects a player has gone linkdead.
+// Handle for BidPlugin opens and closes bids announced by the bot's character in guild or raid chat
 func (p *BidPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
+	p.checkTimers(getTime(), out)
 	if (msg.Channel == "guild" && msg.Source == "You") || (msg.Channel == "raid" && msg.Source == "You") {
 		{ // Check for open bid
 			if p.HandleMultiBids(msg, out) {
@@ -481,7 +612,7 @@ func (p *BidPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
 					count, _ = strconv.Atoi(result[2][1:])
 				}
 				// result[6] == Open timer
-				openTimerMin := 2
+				openTimerMin := defaultOpenTimer()
 				if result[3] != "" {
 					openTimerMin, _ = strconv.Atoi(result[3])
 				}
@@ -506,11 +637,8 @@ func (p *BidPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
 				id, _ := itemDB.FindIDByName(itemName)
 				if id != -1 {
 					if _, ok := p.Bids[id]; ok { // Only close bids if item is in the map
-						item, _ := itemDB.GetItemByID(id)
-						p.Bids[id].CloseBids(out)
-						// Remove item from map
-						delete(p.Bids, id)
-						Info.Printf("Closed bids on %s (x%d)\n", item.Name, count)
+						p.closeBid(id, out)
+						Info.Printf("Closed bids on %s (x%d)\n", itemName, count)
 					} else {
 						Err.Printf("Bids already closed for %s(x%d)\n", itemName, count)
 					}
@@ -518,22 +646,59 @@ func (p *BidPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
 			}
 		}
 	}
-	if msg.Channel == "tell" {
-		// fmt.Printf("Got tell!: %#+v\n", msg)
-		p.HandleTell(msg)
-		// result := p.BidAddMatch.FindStringSubmatch(msg.Msg)
-		// if len(result) >= 2 {
-		// 	itemName := result[1]
-		// 	itemID, _ := itemDB.FindIDByName(itemName)
-		// 	bid, _ := strconv.Atoi(result[2])
-		// 	// fmt.Printf("Result: %#+v itemName: %s itemID: %d bid: %d bidder: %s msg: %#+v\n", result, itemName, itemID, bid, msg.Source, msg)
-		// 	if _, ok := p.Bids[itemID]; ok {
-		// 		if _, ok := Roster[msg.Source]; ok {
-		// 			p.Bids[itemID].AddBid(*Roster[msg.Source], bid, *msg)
-		// 		}
-		// 	}
-		// }
+}
+
+func (p *BidPlugin) Subscriptions() []EventType {
+	return []EventType{EventTell}
+}
+
+// HandleEvent for BidPlugin takes bids sent as tells
+func (p *BidPlugin) HandleEvent(event Event, out io.Writer) {
+	if e, ok := event.(TellEvent); ok {
+		p.HandleTell(e.Log)
+	}
+}
+
+// Tick for BidPlugin closes bids whose timer has run out even when no new log lines arrive
+func (p *BidPlugin) Tick(now time.Time, out io.Writer) {
+	p.checkTimers(now, out)
+}
+
+// checkTimers warns when bids are about to close and closes expired bids if CloseAutomatically is on
+func (p *BidPlugin) checkTimers(now time.Time, out io.Writer) {
+	if !configuration.Bids.CloseAutomatically {
+		return
+	}
+	for id, bid := range p.Bids {
+		if !now.Before(bid.End) {
+			Info.Printf("Timer expired, closing bids on %s (x%d)\n", bid.Item.Name, bid.Quantity)
+			p.closeBid(id, out)
+			continue
+		}
+		if !bid.Warned && bid.Duration > closeWarning && bid.End.Sub(now) <= closeWarning {
+			fmt.Fprintf(out, "> 30 seconds left to bid on %s (x%d)\n", bid.Item.Name, bid.Quantity)
+			bid.Warned = true
+			p.saveBids()
+		}
+	}
+}
+
+// closeBid closes bids on the item, announces the winners and removes it from open bids
+func (p *BidPlugin) closeBid(id int, out io.Writer) {
+	p.Bids[id].CloseBids(out)
+	// Remove item from map
+	delete(p.Bids, id)
+	p.saveBids()
+}
+
+const closeWarning = 30 * time.Second
+
+// defaultOpenTimer is how many minutes bids stay open when the raid leader doesn't say
+func defaultOpenTimer() int {
+	if configuration.Bids.OpenBidTimer > 0 {
+		return configuration.Bids.OpenBidTimer
 	}
+	return 2
 }
 
 func (p *BidPlugin) Info(out io.Writer) {
@@ -549,39 +714,109 @@ func (p *BidPlugin) OutputChannel() int {
 }
 
 func (p BidPlugin) HandleTell(msg *everquest.EqLog) {
+	parts := splitBidTell(msg.Msg)
+	var tell string
+	if len(parts) > 1 {
+		tell = msg.Msg
+	}
+	for _, part := range parts {
+		bidMsg := *msg
+		bidMsg.Msg = part
+		p.handleTellBid(&bidMsg, tell)
+	}
+}
+
+// splitBidTell breaks a tell like "Item A 50 | Item B 25" or "A 50, B 25" into one bid per item,
+// pieces without a bid amount (2nd main talk, commas in item names) stay with their neighbour
+func splitBidTell(tell string) []string {
+	var parts []string
+	var carry string
+	for _, piece := range strings.FieldsFunc(tell, func(r rune) bool { return r == '|' || r == ',' }) {
+		piece = strings.TrimSpace(piece)
+		if carry != "" {
+			piece = carry + ", " + piece
+			carry = ""
+		}
+		if !strings.ContainsAny(strings.Replace(piece, "2nd", "", -1), "0123456789") {
+			if len(parts) > 0 {
+				parts[len(parts)-1] += ", " + piece
+			} else {
+				carry = piece
+			}
+			continue
+		}
+		parts = append(parts, piece)
+	}
+	if carry != "" || len(parts) == 0 {
+		return []string{tell}
+	}
+	return parts
+}
+
+// handleTellBid applies one bid from a tell, tell is the whole message when it held bids on several items
+func (p BidPlugin) handleTellBid(msg *everquest.EqLog, tell string) {
 	if strings.ContainsAny(msg.Msg, "0123456789") {
-		// totalItems := len(p.Bids)
-		// var curItem int
-		for id, item := range p.Bids {
-			// curItem++
-			if strings.Contains(msg.Msg, item.Item.Name) {
-				bidString := strings.Replace(msg.Msg, item.Item.Name, "", 1)
-				bidString = strings.Replace(bidString, "2nd", "", -1) // Remove 2nd main talk
-				bidString = p.BidNumber.FindString(bidString)
-				bid, err := strconv.Atoi(bidString)
-				// fmt.Printf("BidString: %s Bid: %d\n", bidString, bid)
-				if err != nil {
-					Err.Printf("Error converting bid to number, %s\n", err)
+		matches := p.matchOpenBids(msg.Msg)
+		if len(matches) > 1 {
+			reportAmbiguousBid(&p, msg, matches)
+			return
+		}
+		if len(matches) == 0 {
+			ackNoMatch(&p, msg)
+			return
+		}
+		id := matches[0]
+		item := p.Bids[id]
+		bidString := strings.Replace(msg.Msg, item.Item.Name, "", 1)
+		bidString = strings.Replace(bidString, "2nd", "", -1) // Remove 2nd main talk
+		beneficiary := proxyBeneficiary(bidString)
+		if !strings.Contains(msg.Msg, item.Item.Name) { // matched loosely, drop the text naming the item so digits in it are not the bid
+			_, bidString = splitBidAmount(bidString)
+		}
+		bidString = p.BidNumber.FindString(bidString)
+		bid, err := strconv.Atoi(bidString)
+		// fmt.Printf("BidString: %s Bid: %d\n", bidString, bid)
+		if err != nil {
+			Err.Printf("Error converting bid to number, %s\n", err)
+		}
+		if bid >= 0 {
+			source := msg.Source
+			if source == "You" {
+				source = getPlayerName(configuration.Everquest.LogPath)
+			}
+			bidder := source
+			if beneficiary != "" {
+				if !canProxyBid(source) {
+					Warn.Printf("%s tried to bid for %s on %s without proxy rights", source, beneficiary, item.Item.Name)
+					queueTell(source, fmt.Sprintf("Only officers can bid for another member, bid on %s not taken", item.Item.Name))
+					return
 				}
-				if bid >= 0 {
-					source := msg.Source
-					if source == "You" {
-						source = getPlayerName(configuration.Everquest.LogPath)
-					}
-					if _, k := Roster[source]; k {
-						p.Bids[id].AddBid(*Roster[source], bid, *msg)
-					} else {
-						Err.Printf("Could not find player %s in roster\n", source)
-						// TODO: Give them unknown rank
+				bidder = beneficiary
+			}
+			if _, k := Roster[bidder]; k {
+				p.Bids[id].AddBid(*Roster[bidder], bid, *msg)
+				if pos := p.Bids[id].FindBid(bidder); pos >= 0 {
+					p.Bids[id].Bidders[pos].Tell = tell
+					p.Bids[id].Bidders[pos].Proxy = ""
+					if bidder != source {
+						p.Bids[id].Bidders[pos].Proxy = source
 					}
-					return
 				}
-			} // else {
-			// fmt.Printf("Cur: %d Total: %d\n", curItem, totalItems)
-			// if curItem == totalItems {
-			// 	printMessage(msg)
-			// }
-			//}
+				p.saveBids()
+				if bidder != source {
+					Info.Printf("%s bid %d on %s for %s", source, bid, item.Item.Name, bidder)
+					ackProxyBid(p.Bids[id], source, bidder, bid)
+				} else {
+					ackBid(p.Bids[id], source, bid)
+				}
+			} else if bidder != source {
+				Err.Printf("Could not find player %s in roster for a proxy bid from %s\n", bidder, source)
+				queueTell(source, fmt.Sprintf("%s is not on the guild roster, bid on %s not taken", bidder, item.Item.Name))
+			} else {
+				Err.Printf("Could not find player %s in roster\n", source)
+				queueTell(source, fmt.Sprintf("You are not on the guild roster, bid on %s not taken", item.Item.Name))
+				// TODO: Give them unknown rank
+			}
 		}
 	}
 }
@@ -636,15 +871,26 @@ func (p *BidPlugin) OpenBid(itemID int, quantity int, minutes int, seconds int,
 			Item:                 item,
 			Quantity:             quantity,
 			Duration:             (time.Duration(minutes) * time.Minute) + (time.Duration(seconds) * time.Second),
-			Start:                time.Now(),
-			End:                  time.Now().Add(time.Duration(minutes) * time.Minute).Add(time.Duration(seconds) * time.Second),
+			Start:                getTime(),
+			End:                  getTime().Add(time.Duration(minutes) * time.Minute).Add(time.Duration(seconds) * time.Second),
 			Bidders:              bidders,
 			Zone:                 currentZone,
 			SecondMainBidsAsMain: configuration.Bids.SecondMainsBidAsMains,
 			SecondMainMaxBid:     configuration.Bids.SecondMainAsMainMaxBid,
+			ItemRule:             findItemRule(item, currentZone),
 		}
-		p.Bids[itemID].MessageID = DiscordF(configuration.Discord.LootChannelID, "> Bids open on %s (x%d) for %d minutes %d seconds.\n```%s```%s%d", item.Name, quantity, minutes, seconds, getItemDesc(item), configuration.Main.LucyURLPrefix, item.ID)
+		p.Bids[itemID].Key = newBidKey(itemID)
+		var rule string
+		if p.Bids[itemID].ItemRule != nil {
+			rule = fmt.Sprintf("> Rule: %s\n", p.Bids[itemID].ItemRule)
+		}
+		p.Bids[itemID].MessageID = DiscordMessageF(configuration.Discord.LootChannelID, "> Bids open on %s (x%d) for %d minutes %d seconds.\n%s```%s```%s%d", item.Name, quantity, minutes, seconds, rule, getItemDesc(item), configuration.Main.LucyURLPrefix, item.ID)
 		// fmt.Fprintf(out, "> Bids open on %s (x%d) for %d minutes.\n```%s```%s%d", item.Name, quantity, minutes, getItemDesc(item), configuration.Main.LucyURLPrefix, item.ID)
+		p.saveBids()
+		bus.Publish(BidOpenedEvent{Bid: p.Bids[itemID]})
+		if updateDKP && !rosterDKPLoaded {
+			updateRosterDKP() // so bid replies can check balances in the first auction
+		}
 		return nil
 	} else {
 		if p.Bids[itemID].Quantity != quantity { // Modify amount of winners
@@ -655,6 +901,7 @@ func (p *BidPlugin) OpenBid(itemID int, quantity int, minutes int, seconds int,
 				Err.Println(err)
 			}
 			p.Bids[itemID].Quantity = quantity
+			p.saveBids()
 			return nil
 		}
 	}
@@ -663,12 +910,18 @@ func (p *BidPlugin) OpenBid(itemID int, quantity int, minutes int, seconds int,
 
 func (b *OpenBid) AddBid(player DKPHolder, amount int, msg everquest.EqLog) {
 	pos := b.FindBid(player.Name)
+	event := BidEvent{Amount: amount, Message: msg.Msg, Time: msg.T}
 	if pos >= 0 {
-		if amount > configuration.Bids.MinimumBid {
+		if amount > b.minimumBid() {
+			event.Type = bidChange(b.Bidders[pos].AttemptedBid, amount)
 			b.Bidders[pos].AttemptedBid = amount
 			b.Bidders[pos].Message = msg
+			b.Bidders[pos].Events = append(b.Bidders[pos].Events, event)
 			return
 		}
+		event.Type = BidCancelled
+		b.Bidders[pos].Events = append(b.Bidders[pos].Events, event)
+		b.Cancelled = append(b.Cancelled, b.Bidders[pos])
 		b.Bidders = removeBidder(b.Bidders, pos)
 		return
 	} else {
@@ -677,6 +930,15 @@ func (b *OpenBid) AddBid(player DKPHolder, amount int, msg everquest.EqLog) {
 			AttemptedBid: amount,
 			Message:      msg,
 		}
+		if !canEquip(b.Item, player.GuildMember) && b.isClassLocked() {
+			Info.Printf("Rejected %s's bid on %s, their class cannot use it", player.Name, b.Item.Name)
+			return
+		}
+		if cancelled := b.takeCancelled(player.Name); cancelled != nil {
+			bidder.Events = cancelled.Events
+		}
+		event.Type = BidPlaced
+		bidder.Events = append(bidder.Events, event)
 		if !canEquip(b.Item, player.GuildMember) {
 			DiscordF(configuration.Discord.InvestigationChannelID, "```diff\n-A player bid on %s that cannot use it, if it is not cancelled it will be auto investigated. %s\n```", b.Item.Name, b.Item.GetClasses())
 		}
@@ -686,11 +948,15 @@ func (b *OpenBid) AddBid(player DKPHolder, amount int, msg everquest.EqLog) {
 }
 
 func (b *OpenBid) CloseBids(out io.Writer) {
-	b.End = time.Now()
+	b.End = getTime()
 	// Refresh DKP
 	if updateDKP {
 		updateRosterDKP()
 	}
+	// Gate before applying DKP so a dropped bidder gets the max bid of the tier they bid as
+	if configuration.Bids.AttendanceWeighted {
+		b.ApplyAttendanceGates()
+	}
 	// Update max dkp based on attempted amount
 	b.ApplyDKP()
 	// Sort bidders by highest accounting for rank
@@ -698,6 +964,9 @@ func (b *OpenBid) CloseBids(out io.Writer) {
 
 	// Check for ties
 	ties := b.CheckTiesAndApplyWinners()
+	if configuration.Bids.AttendanceWeighted {
+		ties = b.BreakTiesByAttendance(ties)
+	}
 	var tieCount int
 	var tieAnnounce string
 	for tie := range ties {
@@ -714,18 +983,53 @@ func (b *OpenBid) CloseBids(out io.Writer) {
 		tieAnnounce = fmt.Sprintf("%s```", tieAnnounce)
 		// fmt.Fprintf(out, "%s```", tieAnnounce)
 		tied = true
-		err := updateMessage(configuration.Discord.LootChannelID, b.MessageID, tieAnnounce)
+		err := updateHeader(configuration.Discord.LootChannelID, b.MessageID, fmt.Sprintf("> Bids closed on %s (x%d), waiting on a roll off", b.Item.Name, b.Quantity))
+		if err != nil {
+			Err.Println(err)
+		}
+		err = updateMessage(configuration.Discord.LootChannelID, b.MessageID, tieAnnounce)
 		if err != nil {
 			Err.Println(err)
 		}
 	}
 
 	// Find winning cost
-	b.WinningBid = b.FindWinningBid()
+	b.ChargeWinners()
+	if tied {
+		startRollOff(b, ties) // winners are announced and charged once the roll off is decided
+		return
+	}
+	b.AnnounceWinners(false)
+}
 
-	if b.WinningBid < 0 {
-		b.WinningBid = 0
+// ChargeWinners prices every current winner, setting WinningBid to the top winner's price
+func (b *OpenBid) ChargeWinners() ([]Charge, map[string]int) {
+	pricing, err := getPricing(configuration.Bids.Pricing)
+	if err != nil {
+		Err.Printf("%s, using second-price", err.Error())
+		pricing = SecondPrice{}
+	}
+	if b.isFreeRoll() {
+		pricing = FixedPrice{Cost: 0}
+	}
+	charges := b.PriceWinners(pricing)
+	prices := make(map[string]int)
+	b.WinningBid = 0
+	for _, charge := range charges {
+		if charge.Type != "" {
+			continue // credits are not winners
+		}
+		if len(prices) == 0 {
+			b.WinningBid = charge.Cost // announce the top winner's price
+		}
+		prices[charge.Name] = charge.Cost
 	}
+	return charges, prices
+}
+
+// AnnounceWinners posts the final winners, writes the investigation and exports the spent DKP
+func (b *OpenBid) AnnounceWinners(afterRoll bool) {
+	charges, prices := b.ChargeWinners()
 	// Announce winner and include rot if needed
 	winners := b.GetWinnerNames()
 	if len(winners) < b.Quantity {
@@ -735,7 +1039,7 @@ func (b *OpenBid) CloseBids(out io.Writer) {
 			winners = append(winners, "Rot")
 		}
 	}
-	if !tied {
+	if !afterRoll {
 		wonMessage := fmt.Sprintf("> %s (x%d) won for %d DKP", b.Item.Name, b.Quantity, b.WinningBid)
 		err := updateHeader(configuration.Discord.LootChannelID, b.MessageID, wonMessage)
 		if err != nil {
@@ -748,23 +1052,20 @@ func (b *OpenBid) CloseBids(out io.Writer) {
 			Err.Println(err)
 		}
 	}
+	b.Findings = b.Audit()
 	b.GenerateInvestigation()
 
 	winnerMessage := "```"
 
-	var playerWon bool
 	for i, win := range winners {
 		if win == "Rot" {
 			winnerMessage = fmt.Sprintf("%s%d: %s\n", winnerMessage, i+1, win)
 		} else {
-			playerWon = true
-			winnerMessage = fmt.Sprintf("%s%d: %s\tCurrentDKP(%d) - WinningBid(%d) = %d DKP\n", winnerMessage, i+1, win, Roster[win].DKP, b.WinningBid, Roster[win].DKP-b.WinningBid)
+			winnerMessage = fmt.Sprintf("%s%d: %s\tCurrentDKP(%d) - WinningBid(%d) = %d DKP\n", winnerMessage, i+1, win, Roster[win].DKP, prices[win], Roster[win].DKP-prices[win])
 		}
 
 	}
-	if playerWon { // don't require looted for rotted items
-		needsLooted = append(needsLooted, b.Item.Name)
-	}
+	bus.Publish(BidClosedEvent{Bid: b, Winners: b.GetWinnerNames()})
 	winnerMessage = fmt.Sprintf("> Winner(s)\n%s```", winnerMessage)
 	// TODO: Update original message with this info appended
 	err := updateMessage(configuration.Discord.LootChannelID, b.MessageID, winnerMessage)
@@ -775,81 +1076,103 @@ func (b *OpenBid) CloseBids(out io.Writer) {
 	if err != nil {
 		Err.Println(err)
 	}
-	if configuration.Discord.UseDiscord {
-		err = discord.MessageReactionAdd(configuration.Discord.LootChannelID, b.MessageID, configuration.Discord.InvestigationStartEmoji)
+	if configuration.Discord.UseDiscord && b.MessageID != "" {
+		err = discordReaction(configuration.Discord.LootChannelID, b.MessageID, configuration.Discord.InvestigationStartEmoji)
 		if err != nil {
 			Err.Printf("Error adding base reaction: %s", err.Error())
 		}
 	}
 	if b.AutoInvestigate() {
-		uploadArchive(b.MessageID)
+		uploadArchive(b.Key)
 	}
 	// Upload csv of winner dkp changes
-	exportSpentDKP(winners, b.WinningBid, b.Item.Name)
+	if !b.isFreeRoll() {
+		exportSpentDKP(charges, b.Item.Name, b.Key)
+	}
 	// fmt.Fprintf(out, "%s```[%s]", winnerMessage, hash)
 	// Write closed bid investigation file
 
 }
 
+// errNoBidMessage is returned for edits to a bid whose announcement never made it to discord
+var errNoBidMessage = errors.New("bid has no discord message to edit")
+
+// updateMessage appends to a message, queued behind the channel's other messages so edits land in order
 func updateMessage(channelID, messageID, append string) error {
 	if !configuration.Discord.UseDiscord {
 		return nil
 	}
-	msg, err := discord.ChannelMessage(channelID, messageID)
-	if err != nil {
-		return err
+	if messageID == "" {
+		return errNoBidMessage
 	}
-	content := msg.Content
-	content = fmt.Sprintf("%s\n%s\n", content, append)
-	_, err = discord.ChannelMessageEdit(channelID, messageID, content)
-	if err != nil {
+	return outbox.Call(channelID, "edit of "+messageID, func() error {
+		msg, err := discord.ChannelMessage(channelID, messageID)
+		if err != nil {
+			return err
+		}
+		content := msg.Content
+		content = fmt.Sprintf("%s\n%s\n", content, append)
+		_, err = discord.ChannelMessageEdit(channelID, messageID, content)
 		return err
-	}
-	return nil
+	})
 }
 
+// updateHeader replaces the first line of a message, queued like updateMessage
 func updateHeader(channelID, messageID, header string) error {
 	if !configuration.Discord.UseDiscord {
 		return nil
 	}
-	msg, err := discord.ChannelMessage(channelID, messageID)
-	if err != nil {
-		return err
+	if messageID == "" {
+		return errNoBidMessage
 	}
-	content := msg.Content
-	split := strings.Split(content, "\n")
-	split[0] = header
-	content = strings.Join(split, "\n")
-	_, err = discord.ChannelMessageEdit(channelID, messageID, content)
-	if err != nil {
+	return outbox.Call(channelID, "edit of "+messageID, func() error {
+		msg, err := discord.ChannelMessage(channelID, messageID)
+		if err != nil {
+			return err
+		}
+		content := msg.Content
+		split := strings.Split(content, "\n")
+		split[0] = header
+		content = strings.Join(split, "\n")
+		_, err = discord.ChannelMessageEdit(channelID, messageID, content)
 		return err
-	}
-	return nil
+	})
 }
 
 type BidInvestigation struct {
 	WinningBid           int                   `json:"WinningBid"`
 	ItemName             string                `json:"ItemName"`
+	MessageID            string                `json:"MessageID,omitempty"` // discord message the bids were announced in
+	Zone                 string                `json:"Zone,omitempty"`
 	Quantity             int                   `json:"Quantity"`
 	SecondMainBidsAsMain bool                  `json:"SecondMainBidsAsMain"`
 	SecondMainMaxBid     int                   `json:"SecondMainMaxBid"`
+	AttendanceWeighted   bool                  `json:"AttendanceWeighted"`
+	Pricing              string                `json:"Pricing"`
 	Started              string                `json:"Started"`
 	Ended                string                `json:"Ended"`
 	Bidders              []InvestigationBidder `json:"Bidders"`
+	Cancelled            []InvestigationBidder `json:"Cancelled,omitempty"`
+	Findings             []AuditFinding        `json:"Findings,omitempty"`
 	Logs                 []InvestigationLog    `json:"Logs"`
 }
 
 type InvestigationBidder struct {
-	Player       string `json:"Player"`
-	Main         string `json:"Main"`
-	BidAttempted int    `json:"BidAttempted"`
-	BidApplied   int    `json:"BidApplied"`
-	DKP          int    `json:"DKP"`
-	DKPRank      string `json:"DKPRank"`
-	DKPRankValue int    `json:"DKPRankValue"`
-	CanEquip     bool   `json:"CanEquip"`
-	Message      string `json:"Message"`
-	WonOrTied    bool   `json:"WonOrTied"`
+	Player       string     `json:"Player"`
+	Main         string     `json:"Main"`
+	BidAttempted int        `json:"BidAttempted"`
+	BidApplied   int        `json:"BidApplied"`
+	DKP          int        `json:"DKP"`
+	DKPRank      string     `json:"DKPRank"`
+	DKPRankValue int        `json:"DKPRankValue"`
+	CanEquip     bool       `json:"CanEquip"`
+	Message      string     `json:"Message"`
+	WonOrTied    bool       `json:"WonOrTied"`
+	Rule         string     `json:"Rule,omitempty"`
+	Price        int        `json:"Price"`
+	Tell         string     `json:"Tell,omitempty"`
+	Proxy        string     `json:"Proxy,omitempty"`
+	Events       []BidEvent `json:"Events,omitempty"`
 }
 
 type InvestigationLog struct {
@@ -864,21 +1187,34 @@ type InvestigationLog struct {
 	InBidWindow  bool   `json:"InBidWindow"`
 }
 
+func (b *OpenBid) investigationBidder(bidder *Bidder) InvestigationBidder {
+	return InvestigationBidder{
+		Player:       bidder.Player.Name,
+		Main:         getMain(&bidder.Player.GuildMember),
+		BidAttempted: bidder.AttemptedBid,
+		BidApplied:   bidder.Bid,
+		DKP:          bidder.Player.DKP,
+		DKPRank:      DKPRankToString(bidder.Player.DKPRank),
+		DKPRankValue: int(bidder.Player.DKPRank),
+		CanEquip:     canEquip(b.Item, bidder.Player.GuildMember),
+		Message:      bidder.Message.Msg,
+		WonOrTied:    bidder.WonOrTied,
+		Rule:         bidder.Rule,
+		Price:        bidder.Price,
+		Tell:         bidder.Tell,
+		Proxy:        bidder.Proxy,
+		Events:       bidder.Events,
+	}
+}
+
 func (b *OpenBid) GenerateInvestigation() string {
 	var Bidders []InvestigationBidder
 	for _, bidder := range b.Bidders {
-		Bidders = append(Bidders, InvestigationBidder{
-			Player:       bidder.Player.Name,
-			Main:         getMain(&bidder.Player.GuildMember),
-			BidAttempted: bidder.AttemptedBid,
-			BidApplied:   bidder.Bid,
-			DKP:          bidder.Player.DKP,
-			DKPRank:      DKPRankToString(bidder.Player.DKPRank),
-			DKPRankValue: int(bidder.Player.DKPRank),
-			CanEquip:     canEquip(b.Item, bidder.Player.GuildMember),
-			Message:      bidder.Message.Msg,
-			WonOrTied:    bidder.WonOrTied,
-		})
+		Bidders = append(Bidders, b.investigationBidder(bidder))
+	}
+	var Cancelled []InvestigationBidder
+	for _, bidder := range b.Cancelled {
+		Cancelled = append(Cancelled, b.investigationBidder(bidder))
 	}
 	var Logs []InvestigationLog
 	for _, log := range investigation.Messages {
@@ -909,15 +1245,21 @@ func (b *OpenBid) GenerateInvestigation() string {
 	investigation := BidInvestigation{
 		WinningBid:           b.WinningBid,
 		ItemName:             b.Item.Name,
+		Zone:                 b.Zone,
 		Quantity:             b.Quantity,
 		SecondMainBidsAsMain: b.SecondMainBidsAsMain,
 		SecondMainMaxBid:     b.SecondMainMaxBid,
+		AttendanceWeighted:   configuration.Bids.AttendanceWeighted,
+		Pricing:              configuration.Bids.Pricing,
 		Started:              b.Start.Format(time.RFC822),
 		Ended:                b.End.Format(time.RFC822),
 		Bidders:              Bidders,
+		Cancelled:            Cancelled,
+		Findings:             b.Findings,
 		Logs:                 Logs,
 	}
-	hash := b.MessageID
+	investigation.MessageID = b.MessageID
+	hash := b.Key
 	filename := hash + ".json"
 	Info.Printf("Writing archive %s to file", filename)
 	file, err := json.MarshalIndent(investigation, "", " ")
@@ -929,53 +1271,69 @@ func (b *OpenBid) GenerateInvestigation() string {
 	if err != nil {
 		Err.Printf("Error writing archive to file: %s", err.Error())
 	}
-	archives = append(archives, hash) // add to known archive
+	archiveIndex.Add(hash, investigation) // add to known archive
 	return hash
 }
 
+// isBetweenTime compares in whole seconds, log lines only have seconds while bid windows are timed with time.Now
 func isBetweenTime(t time.Time, start, end time.Time) bool {
-	return t.After(start) && t.Before(end)
+	t = t.Truncate(time.Second)
+	return !t.Before(start.Truncate(time.Second)) && !t.After(end.Truncate(time.Second))
 }
 
+// AutoInvestigate is true when the audit found anything at or above the configured severity
 func (b *OpenBid) AutoInvestigate() bool {
-	for _, bidder := range b.Bidders {
-		if bidder.Bid > 0 && !canEquip(b.Item, bidder.Player.GuildMember) {
-			return true
-		}
+	if b.Findings == nil {
+		b.Findings = b.Audit()
 	}
-	if b.WinningBid == 0 && len(b.GetWinnerNames()) != 0 {
-		DiscordF(configuration.Discord.InvestigationChannelID, "```diff\n-Somehow we have a 0 dkp win again auto investigating\n```")
-		return true
+	threshold := investigateSeverity()
+	var hits []string
+	for _, finding := range b.Findings {
+		severity, _ := parseSeverity(finding.Severity)
+		if severity >= threshold {
+			hits = append(hits, fmt.Sprintf("-%s (%s): %s", finding.Rule, finding.Severity, finding.Detail))
+		}
 	}
-	if b.WinningBid > 0 && len(b.GetWinnerNames()) == 0 {
-		DiscordF(configuration.Discord.InvestigationChannelID, "```diff\n-Somehow we have a Rot spending DKP auto investigating\n```")
-		return true
+	if len(hits) == 0 {
+		return false
 	}
-	return false
+	DiscordF(configuration.Discord.InvestigationChannelID, "```diff\n-Auto investigating %s\n%s\n```", b.Item.Name, strings.Join(hits, "\n"))
+	return true
 }
 
-func getArchiveList() []string { // TODO: get directory listing on archives
+// getArchiveList returns the IDs of every archive in the archive folder
+func getArchiveList() []string {
 	var files []string
-
-	root := "./archive"
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error { // This can never have an error TODO: fix this
-		name := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
-		files = append(files, name)
-		return nil
-	})
+	entries, err := ioutil.ReadDir("./archive")
 	if err != nil {
 		Err.Printf("Error reading archives: %s", err.Error())
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		files = append(files, strings.TrimSuffix(entry.Name(), ".json"))
 	}
 	return files
 }
 
-func isArchive(id string) bool {
-	for _, arc := range archives {
-		if arc == id {
-			return true
-		}
-	}
-	return false
+// archiveForMessage finds the archive of the bid announced in a discord message, it is called from discord's goroutine
+func archiveForMessage(messageID string) (string, bool) {
+	var archive string
+	var found bool
+	if !runOnParser(func() {
+		archive, found = archiveIndex.ForMessage(messageID)
+	}) {
+		Warn.Printf("Parser is busy, could not look up the archive for %s", messageID)
+		return "", false
+	}
+	return archive, found
+}
+
+// newBidKey names a bid from when it opened, it is unique as an item only has one open bid at a time
+func newBidKey(itemID int) string {
+	return getTime().Format("20060102150405") + "-" + strconv.Itoa(itemID)
 }
 
 func genUnknownMember(name string) *DKPHolder {
@@ -1019,8 +1377,8 @@ func canEquip(item everquest.Item, player everquest.GuildMember) bool {
 
 func (b *OpenBid) FindWinningBid() int {
 	const DEBUG = false
-	winningBid := configuration.Bids.MinimumBid
-	var winRank DKPRank
+	winningBid := b.minimumBid()
+	var winPriority int
 	if len(b.Bidders) == 0 {
 		return 0 // no one bid, rot
 	}
@@ -1028,7 +1386,7 @@ func (b *OpenBid) FindWinningBid() int {
 	var lastbid int
 	for i, bidder := range b.Bidders {
 		if DEBUG {
-			fmt.Printf("winningBid: %d winRank: %d winners: %d lastbid: %d bid: %d name: %s rank: %d i: %d\n", winningBid, winRank, winners, lastbid, bidder.Bid, bidder.Player.Name, bidder.Player.DKPRank, i)
+			fmt.Printf("winningBid: %d winPriority: %d winners: %d lastbid: %d bid: %d name: %s rank: %d i: %d\n", winningBid, winPriority, winners, lastbid, bidder.Bid, bidder.Player.Name, bidder.Player.DKPRank, i)
 		}
 		if i == 0 && bidder.Bid == 0 {
 			return 0 // ROT
@@ -1037,8 +1395,8 @@ func (b *OpenBid) FindWinningBid() int {
 			continue
 		}
 		winners++ // We don't want to include cancelled bids in winningbid calculations
-		if GetEffectiveDKPRank(bidder.Player.DKPRank) > winRank || winners <= b.Quantity {
-			winRank = GetEffectiveDKPRank(bidder.Player.DKPRank)
+		if tierPriority(bidder.Player.DKPRank) > winPriority || winners <= b.Quantity {
+			winPriority = tierPriority(bidder.Player.DKPRank)
 			lastbid = bidder.Bid
 		} else {
 			if bidder.Bid == lastbid {
@@ -1046,14 +1404,14 @@ func (b *OpenBid) FindWinningBid() int {
 			} else {
 				winningBid = bidder.Bid + 5
 			}
-			if GetEffectiveDKPRank(bidder.Player.DKPRank) != winRank {
-				winningBid = configuration.Bids.MinimumBid
+			if tierPriority(bidder.Player.DKPRank) != winPriority {
+				winningBid = b.minimumBid()
 			}
 			break
 		}
 	}
 	if DEBUG {
-		fmt.Printf("winningBid: %d winRank: %d winners: %d lastbid: %d\n", winningBid, winRank, winners, lastbid)
+		fmt.Printf("winningBid: %d winPriority: %d winners: %d lastbid: %d\n", winningBid, winPriority, winners, lastbid)
 	}
 	return winningBid
 }
@@ -1066,7 +1424,7 @@ func (b *OpenBid) CheckTiesAndApplyWinners() map[string]interface{} { // We are
 		return tiedPlayers // more items than potential ties, so no ties
 	}
 	var tieBid int
-	var tiedRank DKPRank
+	var tiedPriority int
 	var validBids int
 	for i := range b.Bidders {
 		if b.Bidders[i].Bid == 0 {
@@ -1076,14 +1434,14 @@ func (b *OpenBid) CheckTiesAndApplyWinners() map[string]interface{} { // We are
 		if validBids <= b.Quantity && tieBid != b.Bidders[i].Bid {
 			b.Bidders[i].WonOrTied = true
 			tieBid = b.Bidders[i].Bid
-			tiedRank = GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank)
+			tiedPriority = tierPriority(b.Bidders[i].Player.DKPRank)
 			tiedPlayers = make(map[string]interface{}) // clear the tied, we might have had guaranteed winners that tied
 			continue                                   // not a tie, check next bid
 		}
 		if validBids > b.Quantity && tieBid != b.Bidders[i].Bid {
 			return tiedPlayers // we have found all the possible tie bids, so we are done
 		}
-		if tieBid == b.Bidders[i].Bid && GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == tiedRank {
+		if tieBid == b.Bidders[i].Bid && (b.isFreeRoll() || tierPriority(b.Bidders[i].Player.DKPRank) == tiedPriority) {
 			b.Bidders[i].WonOrTied = true
 			tiedPlayers[b.Bidders[i-1].Player.Name] = nil // ensure the original tie bid is here
 			tiedPlayers[b.Bidders[i].Player.Name] = nil
@@ -1102,51 +1460,16 @@ func (b *OpenBid) GetWinnerNames() []string {
 	return winners
 }
 
-func (b *OpenBid) SortBids() { // TODO: This isn't working correctly -> fixed, but check testing
-	/*
-		INACTIVE = iota
-		SOCIAL
-		ALT
-		RECRUIT
-		SECONDMAIN
-		MAIN
-	*/
+func (b *OpenBid) SortBids() {
 	// Sort by Bid
-	// fmt.Printf("Pre-Sort\n")
-	// b.printBidders()
 	sort.Sort(sort.Reverse(ByBid(b.Bidders)))
-	// Remove each effective rank into seperate slice
-	var mains, secondmains, recruits, alts, socials, inactives []*Bidder
-	for i := range b.Bidders {
-		if GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == MAIN {
-			mains = append(mains, b.Bidders[i])
-		}
-		if GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == SECONDMAIN {
-			secondmains = append(secondmains, b.Bidders[i])
-		}
-		if GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == RECRUIT {
-			recruits = append(recruits, b.Bidders[i])
-		}
-		if GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == ALT {
-			alts = append(alts, b.Bidders[i])
-		}
-		if GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == SOCIAL {
-			socials = append(socials, b.Bidders[i])
-		}
-		if GetEffectiveDKPRank(b.Bidders[i].Player.DKPRank) == INACTIVE {
-			inactives = append(inactives, b.Bidders[i])
-		}
+	if b.isFreeRoll() {
+		return // free rolls go to those who can use the item, then the roll, whatever their tier
 	}
-	// nullify b.bidders
-	b.Bidders = nil
-	b.Bidders = append(b.Bidders, mains...)
-	b.Bidders = append(b.Bidders, secondmains...)
-	b.Bidders = append(b.Bidders, recruits...)
-	b.Bidders = append(b.Bidders, alts...)
-	b.Bidders = append(b.Bidders, socials...)
-	b.Bidders = append(b.Bidders, inactives...)
-	// fmt.Printf("Post-Sort\n")
-	// b.printBidders()
+	// Group by the priority of the tier each bidder bids in, keeping the bid order inside a tier
+	sort.SliceStable(b.Bidders, func(i, j int) bool {
+		return tierPriority(b.Bidders[i].Player.DKPRank) > tierPriority(b.Bidders[j].Player.DKPRank)
+	})
 }
 
 func (b *OpenBid) printBidders() {
@@ -1165,7 +1488,7 @@ type ByRank []*Bidder
 
 func (a ByRank) Len() int { return len(a) }
 func (a ByRank) Less(i, j int) bool {
-	return GetEffectiveDKPRank(a[i].Player.DKPRank) < GetEffectiveDKPRank(a[j].Player.DKPRank)
+	return tierPriority(a[i].Player.DKPRank) < tierPriority(a[j].Player.DKPRank)
 }
 func (a ByRank) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
@@ -1179,50 +1502,62 @@ func (a ByBidAndRank) Less(i, j int) bool {
 	if a[i].Bid > a[j].Bid {
 		return false
 	}
-	return GetEffectiveDKPRank(a[i].Player.DKPRank) < GetEffectiveDKPRank(a[j].Player.DKPRank)
+	return tierPriority(a[i].Player.DKPRank) < tierPriority(a[j].Player.DKPRank)
 }
 func (a ByBidAndRank) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
 func GetEffectiveDKPRank(rank DKPRank) DKPRank {
-	if configuration.Bids.SecondMainsBidAsMains && rank == SECONDMAIN {
-		return MAIN
+	effective, err := parseDKPRank(getEffectiveTier(rank).Name)
+	if err != nil {
+		return rank
 	}
-	return rank
+	return effective
 }
 
 func (b *OpenBid) ApplyDKP() {
 	for i := range b.Bidders {
-		if b.Bidders[i].AttemptedBid > configuration.Bids.MaxBid { // This needs to be a smaller number so overflows don't happen and break rounding
-			b.Bidders[i].AttemptedBid = configuration.Bids.MaxBid
-		}
-		b.Bidders[i].Player.DKP = Roster[getMain(&b.Bidders[i].Player.GuildMember)].DKP // Apply the latest roster values to the bidder -> move to a function and apply secondmain/alt dkp
-		if b.Bidders[i].AttemptedBid > b.Bidders[i].Player.DKP {
-			if b.Bidders[i].Player.DKP < configuration.Bids.MinimumBid { // Todo: Need to make a test for this
-				b.Bidders[i].Bid = configuration.Bids.MinimumBid
-			} else {
-				b.Bidders[i].Bid = b.Bidders[i].Player.DKP
-			}
+		b.applyDKP(b.Bidders[i])
+	}
+}
+
+// applyDKP works out the bid that counts for a bidder from what they attempted and the DKP they have
+func (b *OpenBid) applyDKP(bidder *Bidder) {
+	if bidder.AttemptedBid > b.maxBid() { // This needs to be a smaller number so overflows don't happen and break rounding
+		bidder.AttemptedBid = b.maxBid()
+	}
+	bidder.Player.DKP = Roster[getMain(&bidder.Player.GuildMember)].DKP // Apply the latest roster values to the bidder -> move to a function and apply secondmain/alt dkp
+	if bidder.AttemptedBid > bidder.Player.DKP {
+		if bidder.Player.DKP < b.minimumBid() { // Todo: Need to make a test for this
+			bidder.Bid = b.minimumBid()
 		} else {
-			b.Bidders[i].Bid = b.Bidders[i].AttemptedBid
-		}
-		if b.Bidders[i].AttemptedBid > 0 && b.Bidders[i].AttemptedBid < configuration.Bids.MinimumBid {
-			b.Bidders[i].Bid = configuration.Bids.MinimumBid
-		}
-		if b.Bidders[i].AttemptedBid%configuration.Bids.Increments != 0 && b.Bidders[i].Bid%configuration.Bids.Increments != 0 { // if you fail to bid in correct increments, we are setting you to minimum bid
-			// We should round down
-			rounded := roundDown(b.Bidders[i].AttemptedBid)
-			// fmt.Printf("Rounded: %d\n", rounded)
-			if rounded < configuration.Bids.MinimumBid {
-				rounded = configuration.Bids.MinimumBid
-			}
-			// fmt.Printf("Rounded Post: %d\n", rounded)
-			b.Bidders[i].Bid = rounded
+			bidder.Bid = bidder.Player.DKP
 		}
-		if b.Bidders[i].AttemptedBid <= 0 { // Cancelled Bid
-			b.Bidders[i].Bid = 0
+	} else {
+		bidder.Bid = bidder.AttemptedBid
+	}
+	if bidder.AttemptedBid > 0 && bidder.AttemptedBid < b.minimumBid() {
+		bidder.Bid = b.minimumBid()
+	}
+	if bidder.AttemptedBid%configuration.Bids.Increments != 0 && bidder.Bid%configuration.Bids.Increments != 0 { // if you fail to bid in correct increments, we are setting you to minimum bid
+		// We should round down
+		rounded := roundDown(bidder.AttemptedBid)
+		// fmt.Printf("Rounded: %d\n", rounded)
+		if rounded < b.minimumBid() {
+			rounded = b.minimumBid()
 		}
-		if configuration.Bids.SecondMainsBidAsMains && b.Bidders[i].Player.DKPRank == SECONDMAIN && b.Bidders[i].Bid > configuration.Bids.SecondMainAsMainMaxBid { // limit to 200 dkp always on secondmains for primary content
-			b.Bidders[i].Bid = configuration.Bids.SecondMainAsMainMaxBid
+		// fmt.Printf("Rounded Post: %d\n", rounded)
+		bidder.Bid = rounded
+	}
+	if bidder.AttemptedBid <= 0 { // Cancelled Bid
+		bidder.Bid = 0
+	}
+	if maxBid := getTier(bidder.Player.DKPRank).MaxBid; maxBid > 0 && bidder.Bid > maxBid { // tier caps, like 200 dkp on secondmains for primary content
+		bidder.Bid = maxBid
+	}
+	if b.isFreeRoll() && bidder.Bid > 0 { // everyone rolls, those that can use it first
+		bidder.Bid = 1
+		if canEquip(b.Item, bidder.Player.GuildMember) {
+			bidder.Bid = 2
 		}
 	}
 }
@@ -1309,7 +1644,7 @@ func (p *BidPlugin) HandleMultiBids(msg *everquest.EqLog, out io.Writer) bool {
 	mins, err := strconv.Atoi(minStr)
 	if err != nil {
 		log.Printf("Error converting min to int: %s\n", err)
-		mins = 2
+		mins = defaultOpenTimer()
 	}
 	// TODO: get seconds?
 
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,13 +30,13 @@ func TestBidOpen(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// fmt.Printf("ID: %d\n", id)
 	got := plug.Bids[id].Quantity
 	want := 1
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -50,13 +52,13 @@ func TestBidOpenTellsTo(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// fmt.Printf("ID: %d\n", id)
 	got := plug.Bids[id].Quantity
 	want := 1
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -72,13 +74,13 @@ func TestBidTimeWithSeconds(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	id, _ := itemDB.FindIDByName("Gloves of the Unseen")
 	// fmt.Printf("ID: %d\n", id)
 	got := plug.Bids[id].Duration.Seconds()
 	want := 150.0
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %f, want %f", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %f, want %f", got, want)
 	}
 }
 
@@ -94,13 +96,13 @@ func TestBidTimeWithoutSeconds(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// fmt.Printf("ID: %d\n", id)
 	got := plug.Bids[id].Duration.Seconds()
 	want := 120.0
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %f, want %f", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %f, want %f", got, want)
 	}
 }
 
@@ -117,19 +119,19 @@ func TestBidChangeQuantity(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	oQuantity := plug.Bids[id].Quantity
 	msgTwo := new(everquest.EqLog)
 	msgTwo.Channel = "guild"
 	msgTwo.Msg = "Cloth Capx3 bids to Bids, pst 2min"
 	msgTwo.Source = "You"
 	msgTwo.T = time.Now()
-	plug.Handle(msgTwo, &b)
+	publishLog(plug, msgTwo, &b)
 	nQuantity := plug.Bids[id].Quantity
 	// got := b.String()
 	// want := "Bids open on Cloth Cap(x1) for 2 minutes.\n"
 	if nQuantity != 3 && oQuantity != nQuantity {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", oQuantity, nQuantity)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", oQuantity, nQuantity)
 	}
 }
 
@@ -156,7 +158,7 @@ func TestBidClose(t *testing.T) {
 		Bidders:  []*Bidder{},
 	}
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	var bidClosed bool
 	if _, ok := plug.Bids[id]; !ok {
 		bidClosed = true
@@ -164,7 +166,7 @@ func TestBidClose(t *testing.T) {
 	got := bidClosed
 	want := true
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &t) = %t, want %t", got, want)
+		t.Errorf("publishLog(ldplug, msg, &t) = %t, want %t", got, want)
 	}
 }
 
@@ -175,7 +177,7 @@ func TestGetDKPRankInactive(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(INACTIVE)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -187,7 +189,7 @@ func TestGetDKPRankLeaderMain(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(MAIN)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -200,7 +202,7 @@ func TestGetDKPRankLeaderSecondMain(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(SECONDMAIN)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 func TestGetDKPRankOfficerSecondMain(t *testing.T) {
@@ -212,7 +214,7 @@ func TestGetDKPRankOfficerSecondMain(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(SECONDMAIN)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -223,7 +225,7 @@ func TestGuildHotReload(t *testing.T) {
 	got := getDKPRank(&Roster["Struummin"].GuildMember)
 	want := DKPRank(SECONDMAIN)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -236,7 +238,7 @@ func TestGetDKPRankAltSecondMain(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(SECONDMAIN)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -288,7 +290,7 @@ func TestGetDKPRankSecondMainNoApostraphe(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(SECONDMAIN)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -299,7 +301,7 @@ func TestGetDKPRankRecruit(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(RECRUIT)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -310,7 +312,7 @@ func TestGetDKPRankSocial(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(SOCIAL)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -322,7 +324,7 @@ func TestGetDKPRankAlt(t *testing.T) {
 	got := getDKPRank(member)
 	want := DKPRank(ALT)
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", DKPRankToString(got), DKPRankToString(want))
 	}
 }
 
@@ -339,18 +341,18 @@ func TestBidAdd(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 500"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("Mortimus")
 	want := 0
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -371,7 +373,7 @@ func TestBidAddAmount(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	bidAmount := r1.Int()
@@ -379,16 +381,16 @@ func TestBidAddAmount(t *testing.T) {
 	add.T = time.Now()
 
 	add.Msg = fmt.Sprintf("%s %d", randomItem.Name, bidAmount)
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName(randomItem.Name)
 	bidder := plug.Bids[id].FindBid("Mortimus")
 	if bidder < 0 {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %s", bidder, "positive number")
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %s", bidder, "positive number")
 	}
 	got := plug.Bids[id].Bidders[bidder].AttemptedBid
 	want := bidAmount
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -405,13 +407,13 @@ func TestBidApply(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 500"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("Mortimus")
 	Roster["Mortimus"].DKP = 2000
@@ -421,7 +423,7 @@ func TestBidApply(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := 500
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -438,13 +440,13 @@ func TestBidApplyTooMuch(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 5000"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("Mortimus")
 	Roster["Mortimus"].DKP = 2000
@@ -453,7 +455,7 @@ func TestBidApplyTooMuch(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := 2000
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -471,13 +473,13 @@ func TestBidApplyBelowMin(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 5"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("Mortimus")
 	Roster["Mortimus"].DKP = 2000
@@ -486,7 +488,7 @@ func TestBidApplyBelowMin(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := configuration.Bids.MinimumBid
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -504,13 +506,13 @@ func TestBidApplyNoIncrement(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 13"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("Mortimus")
 	Roster["Mortimus"].DKP = 2000
@@ -519,7 +521,7 @@ func TestBidApplyNoIncrement(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := configuration.Bids.MinimumBid
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -536,13 +538,13 @@ func TestBidApplyCancelledBid(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 0"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("Mortimus")
 	Roster["Mortimus"].DKP = 2000
@@ -551,7 +553,7 @@ func TestBidApplyCancelledBid(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := 0
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -570,19 +572,19 @@ func TestBidApplyNerfedSecondMain(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 300"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 3000"
 	secondadd.Source = "Milliardo"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// maingot := plug.Bids[id].FindBid("Mortimus")
@@ -595,7 +597,7 @@ func TestBidApplyNerfedSecondMain(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[secondgot].Bid
 	want := configuration.Bids.SecondMainAsMainMaxBid
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -618,19 +620,19 @@ func TestTiesSameRank(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 300"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 300"
 	secondadd.Source = "Penelo"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	plug.Bids[id].ApplyDKP()
@@ -639,7 +641,7 @@ func TestTiesSameRank(t *testing.T) {
 	got := len(ties)
 	want := 2
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -658,25 +660,25 @@ func TestTiesSameRankMulti(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 300"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 300"
 	secondadd.Source = "Milliardo"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 300"
 	thirdadd.Source = "Penelo"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	maingot := plug.Bids[id].FindBid("Mortimus")
@@ -694,7 +696,7 @@ func TestTiesSameRankMulti(t *testing.T) {
 	got := len(ties)
 	want := 3
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -713,25 +715,25 @@ func TestTiesSecondAndThirdTie(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 500"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 300"
 	secondadd.Source = "Zortax"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 300"
 	thirdadd.Source = "Penelo"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	Roster["Mortimus"].DKP = 2000
@@ -746,7 +748,7 @@ func TestTiesSecondAndThirdTie(t *testing.T) {
 	got := len(ties)
 	want := 2
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -765,25 +767,25 @@ func TestTiesCancelledTies(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 0"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 0"
 	secondadd.Source = "Zortax"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 0"
 	thirdadd.Source = "Penelo"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	Roster["Mortimus"].DKP = 2000
@@ -798,7 +800,7 @@ func TestTiesCancelledTies(t *testing.T) {
 	got := len(ties)
 	want := 0
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -817,25 +819,25 @@ func TestTiesDiffRank(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 100"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 100"
 	secondadd.Source = "Rokem"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 100"
 	thirdadd.Source = "Penelo"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	Roster["Mortimus"].DKP = 2000
@@ -850,7 +852,7 @@ func TestTiesDiffRank(t *testing.T) {
 	got := len(ties)
 	want := 2
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -869,25 +871,25 @@ func TestTiesDiffRankCancelledBidSecondMain(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 100"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 100"
 	secondadd.Source = "Milliardo"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 0"
 	thirdadd.Source = "Penelo"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	Roster["Mortimus"].DKP = 2000
@@ -902,7 +904,7 @@ func TestTiesDiffRankCancelledBidSecondMain(t *testing.T) {
 	got := len(ties)
 	want := 2
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -921,25 +923,25 @@ func TestTiesDiffRankCancelledBid(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 0"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 100"
 	secondadd.Source = "Rokem"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 100"
 	thirdadd.Source = "Glavin"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	Roster["Mortimus"].DKP = 2000
@@ -954,7 +956,7 @@ func TestTiesDiffRankCancelledBid(t *testing.T) {
 	got := len(ties)
 	want := 2
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -973,25 +975,25 @@ func TestTiesMoreItemsThanTies(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 100"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 100"
 	secondadd.Source = "Zortax"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 100"
 	thirdadd.Source = "Penelo"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	Roster["Mortimus"].DKP = 2000
@@ -1006,7 +1008,7 @@ func TestTiesMoreItemsThanTies(t *testing.T) {
 	got := len(ties)
 	want := 0
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -1025,13 +1027,13 @@ func TestBidApplyNoDKP(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 100"
 	add.Source = "NotReal"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	got := plug.Bids[id].FindBid("NotReal")
 
@@ -1039,7 +1041,7 @@ func TestBidApplyNoDKP(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := 10
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -1059,13 +1061,13 @@ func TestBidApplyNoDKPSapphire(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Sapphire of Capricious Magic 100"
 	add.Source = "NotReal"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	id, _ := itemDB.FindIDByName("Sapphire of Capricious Magic")
 	got := plug.Bids[id].FindBid("NotReal")
 
@@ -1073,7 +1075,7 @@ func TestBidApplyNoDKPSapphire(t *testing.T) {
 	appliedBid := plug.Bids[id].Bidders[got].Bid
 	want := 10
 	if appliedBid != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", appliedBid, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", appliedBid, want)
 	}
 }
 
@@ -1102,37 +1104,37 @@ func TestBidGitHubIssue34(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 75"
 	add.Source = "Rabidtiger"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 20"
 	secondadd.Source = "Yilumi"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 20"
 	thirdadd.Source = "Nistalkin"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	fourthadd := new(everquest.EqLog)
 	fourthadd.Channel = "tell"
 	fourthadd.Msg = "Cloth Cap 20"
 	fourthadd.Source = "Boseth"
 	fourthadd.T = time.Now()
-	plug.Handle(fourthadd, &b)
+	publishLog(plug, fourthadd, &b)
 	fifthadd := new(everquest.EqLog)
 	fifthadd.Channel = "tell"
 	fifthadd.Msg = "Cloth Cap 20"
 	fifthadd.Source = "Bremen"
 	fifthadd.T = time.Now()
-	plug.Handle(fifthadd, &b)
+	publishLog(plug, fifthadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	plug.Bids[id].CloseBids(io.Discard)
@@ -1168,37 +1170,37 @@ func TestBidWinningPlusFive(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 75"
 	add.Source = "Rabidtiger"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 65"
 	secondadd.Source = "Yilumi"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 60"
 	thirdadd.Source = "Nistalkin"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	fourthadd := new(everquest.EqLog)
 	fourthadd.Channel = "tell"
 	fourthadd.Msg = "Cloth Cap 20"
 	fourthadd.Source = "Boseth"
 	fourthadd.T = time.Now()
-	plug.Handle(fourthadd, &b)
+	publishLog(plug, fourthadd, &b)
 	fifthadd := new(everquest.EqLog)
 	fifthadd.Channel = "tell"
 	fifthadd.Msg = "Cloth Cap 10"
 	fifthadd.Source = "Bremen"
 	fifthadd.T = time.Now()
-	plug.Handle(fifthadd, &b)
+	publishLog(plug, fifthadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// plug.Bids[id].ApplyDKP()
@@ -1230,19 +1232,19 @@ func TestTiesSameRankTiedWinningBid(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 300"
 	add.Source = "Mortimus"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 300"
 	secondadd.Source = "Penelo"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// plug.Bids[id].ApplyDKP()
@@ -1251,7 +1253,7 @@ func TestTiesSameRankTiedWinningBid(t *testing.T) {
 	got := plug.Bids[id].WinningBid
 	want := 300
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 }
 
@@ -1274,19 +1276,19 @@ func TestBidGitHubIssue40(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 10"
 	add.Source = "Greyvvolf"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 125"
 	secondadd.Source = "Canniblepper"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// plug.Bids[id].ApplyDKP()
@@ -1295,12 +1297,12 @@ func TestBidGitHubIssue40(t *testing.T) {
 	got := plug.Bids[id].WinningBid
 	want := 10
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %d, want %d", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %d, want %d", got, want)
 	}
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Canniblepper"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 }
 
@@ -1325,25 +1327,25 @@ func TestBidSingleMinBidWinner(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 700"
 	add.Source = "Guzz"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 60"
 	secondadd.Source = "Flappyhands"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Cloth Cap 0"
 	thirdadd.Source = "Boogabooga"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// plug.Bids[id].ApplyDKP()
@@ -1385,25 +1387,25 @@ func TestBidMultiMinBidWinner(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Greaves of Furious Might 700"
 	add.Source = "Guzz"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Greaves of Furious Might 60 "
 	secondadd.Source = "Flappyhands"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Greaves of Furious Might 0"
 	thirdadd.Source = "Boogabooga"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Greaves of Furious Might")
 	// plug.Bids[id].ApplyDKP()
@@ -1447,19 +1449,19 @@ func TestBidSingleMinBidWinner2(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 325"
 	add.Source = "Glooping"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 0"
 	secondadd.Source = "Yilumi"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// plug.Bids[id].ApplyDKP()
@@ -1498,19 +1500,19 @@ func TestBidMultiDiffBids(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Cloth Cap 200"
 	add.Source = "Drae"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Cloth Cap 40"
 	secondadd.Source = "Penelo"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Cloth Cap")
 	// plug.Bids[id].ApplyDKP()
@@ -1556,37 +1558,37 @@ func TestBidTripleBidWinner(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Mossy Enchanted Stone 200"
 	add.Source = "Voltha"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Mossy Enchanted Stone 110"
 	secondadd.Source = "Mayfair"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Mossy Enchanted Stone 45"
 	thirdadd.Source = "Boogabooga"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	fouradd := new(everquest.EqLog)
 	fouradd.Channel = "tell"
 	fouradd.Msg = "Mossy Enchanted Stone 15"
 	fouradd.Source = "Guzz"
 	fouradd.T = time.Now()
-	plug.Handle(fouradd, &b)
+	publishLog(plug, fouradd, &b)
 	fiveadd := new(everquest.EqLog)
 	fiveadd.Channel = "tell"
 	fiveadd.Msg = "Mossy Enchanted Stone 75"
 	fiveadd.Source = "Sitoknight"
 	fiveadd.T = time.Now()
-	plug.Handle(fiveadd, &b)
+	publishLog(plug, fiveadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Mossy Enchanted Stone")
 	// plug.Bids[id].ApplyDKP()
@@ -1600,7 +1602,7 @@ func TestBidTripleBidWinner(t *testing.T) {
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Voltha"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 }
 
@@ -1645,79 +1647,79 @@ func TestBidMultiItemBidIssue45(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`(.+[\w\d])\s+(\d+).*`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "Mossy Enchanted Stone 450"
 	add.Source = "Blepper"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "Mossy Enchanted Stone 25"
 	secondadd.Source = "Renab"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "Mossy Enchanted Stone 305"
 	thirdadd.Source = "Yilumi"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	fouradd := new(everquest.EqLog)
 	fouradd.Channel = "tell"
 	fouradd.Msg = "Mossy Enchanted Stone 300"
 	fouradd.Source = "Mortimus"
 	fouradd.T = time.Now()
-	plug.Handle(fouradd, &b)
+	publishLog(plug, fouradd, &b)
 	fiveadd := new(everquest.EqLog)
 	fiveadd.Channel = "tell"
 	fiveadd.Msg = "Mossy Enchanted Stone 210"
 	fiveadd.Source = "Ravnor"
 	fiveadd.T = time.Now()
-	plug.Handle(fiveadd, &b)
+	publishLog(plug, fiveadd, &b)
 	sixadd := new(everquest.EqLog)
 	sixadd.Channel = "tell"
 	sixadd.Msg = "Mossy Enchanted Stone 150"
 	sixadd.Source = "Bipp"
 	sixadd.T = time.Now()
-	plug.Handle(sixadd, &b)
+	publishLog(plug, sixadd, &b)
 	sevnadd := new(everquest.EqLog)
 	sevnadd.Channel = "tell"
 	sevnadd.Msg = "Mossy Enchanted Stone 110"
 	sevnadd.Source = "Yzzy"
 	sevnadd.T = time.Now()
-	plug.Handle(sevnadd, &b)
+	publishLog(plug, sevnadd, &b)
 	eightadd := new(everquest.EqLog)
 	eightadd.Channel = "tell"
 	eightadd.Msg = "Mossy Enchanted Stone 25"
 	eightadd.Source = "Glert"
 	eightadd.T = time.Now()
-	plug.Handle(eightadd, &b)
+	publishLog(plug, eightadd, &b)
 	nineadd := new(everquest.EqLog)
 	nineadd.Channel = "tell"
 	nineadd.Msg = "Mossy Enchanted Stone 20"
 	nineadd.Source = "Raage"
 	nineadd.T = time.Now()
-	plug.Handle(nineadd, &b)
+	publishLog(plug, nineadd, &b)
 	tenadd := new(everquest.EqLog)
 	tenadd.Channel = "tell"
 	tenadd.Msg = "Mossy Enchanted Stone 15"
 	tenadd.Source = "Ryder"
 	tenadd.T = time.Now()
-	plug.Handle(tenadd, &b)
+	publishLog(plug, tenadd, &b)
 	eleadd := new(everquest.EqLog)
 	eleadd.Channel = "tell"
 	eleadd.Msg = "Mossy Enchanted Stone 10"
 	eleadd.Source = "Gausbert"
 	eleadd.T = time.Now()
-	plug.Handle(eleadd, &b)
+	publishLog(plug, eleadd, &b)
 	twelveadd := new(everquest.EqLog)
 	twelveadd.Channel = "tell"
 	twelveadd.Msg = "Mossy Enchanted Stone 400"
 	twelveadd.Source = "Liqqy"
 	twelveadd.T = time.Now()
-	plug.Handle(twelveadd, &b)
+	publishLog(plug, twelveadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Mossy Enchanted Stone")
 	// plug.Bids[id].ApplyDKP()
@@ -1732,12 +1734,12 @@ func TestBidMultiItemBidIssue45(t *testing.T) {
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Blepper"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 	got3 := plug.Bids[id].Bidders[1].Player.Name
 	want3 := "Yilumi"
 	if got3 != want3 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got3, want3)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got3, want3)
 	}
 }
 
@@ -1798,31 +1800,31 @@ func TestMissingBidsIssue47(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`'(.+[\w\d])\s+(\d+).*'`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "'Bulwark of Living Stone 999999999999999999999999999'"
 	secondadd.Source = "Bremen"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "'Bulwark of Living Stone  800 '"
 	thirdadd.Source = "Zortax"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "'Bulwark of Living Stone 600'"
 	add.Source = "Draeadin"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	fouradd := new(everquest.EqLog)
 	fouradd.Channel = "tell"
 	fouradd.Msg = "'Bulwark of Living Stone  805'"
 	fouradd.Source = "Raage"
 	fouradd.T = time.Now()
-	plug.Handle(fouradd, &b)
+	publishLog(plug, fouradd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Bulwark of Living Stone")
 	// plug.Bids[id].ApplyDKP()
@@ -1837,7 +1839,7 @@ func TestMissingBidsIssue47(t *testing.T) {
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Raage"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 }
 
@@ -1862,19 +1864,19 @@ func TestRoundDownIssue46(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`'(.+[\w\d])\s+(\d+).*'`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "'Bulwark of Living Stone 999999999999999999999999999'"
 	secondadd.Source = "Bremen"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "'Bulwark of Living Stone 200'"
 	add.Source = "Draeadin"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Bulwark of Living Stone")
 	// plug.Bids[id].ApplyDKP()
@@ -1889,7 +1891,7 @@ func TestRoundDownIssue46(t *testing.T) {
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Bremen"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 }
 
@@ -1914,19 +1916,19 @@ func TestItemBidNoSpace(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`'(.+[\w\d])\s+(\d+).*'`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "'Bulwark of Living Stone 999999999999999999999999999'"
 	secondadd.Source = "Bremen"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "'Bulwark of Living Stone200'"
 	add.Source = "Draeadin"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Bulwark of Living Stone")
 	// plug.Bids[id].ApplyDKP()
@@ -1941,7 +1943,7 @@ func TestItemBidNoSpace(t *testing.T) {
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Bremen"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 }
 
@@ -1971,25 +1973,25 @@ func TestRoundDownIssue51(t *testing.T) {
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	plug.BidAddMatch, _ = regexp.Compile(`'(.+[\w\d])\s+(\d+).*'`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	secondadd := new(everquest.EqLog)
 	secondadd.Channel = "tell"
 	secondadd.Msg = "'Bulwark of Living Stone 999'"
 	secondadd.Source = "Silvae"
 	secondadd.T = time.Now()
-	plug.Handle(secondadd, &b)
+	publishLog(plug, secondadd, &b)
 	add := new(everquest.EqLog)
 	add.Channel = "tell"
 	add.Msg = "'Bulwark of Living Stone 855'"
 	add.Source = "Geban"
 	add.T = time.Now()
-	plug.Handle(add, &b)
+	publishLog(plug, add, &b)
 	thirdadd := new(everquest.EqLog)
 	thirdadd.Channel = "tell"
 	thirdadd.Msg = "'Bulwark of Living Stone 800'"
 	thirdadd.Source = "Karalaine"
 	thirdadd.T = time.Now()
-	plug.Handle(thirdadd, &b)
+	publishLog(plug, thirdadd, &b)
 	//----------------
 	id, _ := itemDB.FindIDByName("Bulwark of Living Stone")
 	// plug.Bids[id].ApplyDKP()
@@ -2004,7 +2006,7 @@ func TestRoundDownIssue51(t *testing.T) {
 	got2 := plug.Bids[id].Bidders[0].Player.Name
 	want2 := "Geban"
 	if got2 != want2 {
-		t.Errorf("ldplug.Handle(msg, &b) = %s, want %s", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %s, want %s", got2, want2)
 	}
 }
 
@@ -2020,25 +2022,25 @@ func TestBidMultiOpen(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	id1, _ := itemDB.FindIDByName("Scales of the Cragbeast Queen")
 	// fmt.Printf("ID: %d\n", id)
 	got := plug.Bids[id1].Quantity
 	want := 1
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 	id2, _ := itemDB.FindIDByName("Phosphorescent Bile")
 	// fmt.Printf("ID: %d\n", id)
 	got2 := plug.Bids[id2].Quantity
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got2, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got2, want)
 	}
 	id3, _ := itemDB.FindIDByName("Misshapen Cragbeast Flesh")
 	// fmt.Printf("ID: %d\n", id)
 	got3 := plug.Bids[id3].Quantity
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got3, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got3, want)
 	}
 }
 
@@ -2054,25 +2056,191 @@ func TestBidMultiOpenMultiQuantity(t *testing.T) {
 	plug.BidCloseMatch, _ = regexp.Compile(`(.+?)(x\d)?\s+([Bb][Ii][Dd][Ss])?([Tt][Ee][Ll][Ll][Ss])?\sto\s.+,?.+([Cc][Ll][Oo][Ss][Ee][Dd]).*`)
 	plug.BidNumber, _ = regexp.Compile(`\d+`)
 	var b bytes.Buffer
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	id1, _ := itemDB.FindIDByName("Scales of the Cragbeast Queen")
 	// fmt.Printf("ID: %d\n", id)
 	got := plug.Bids[id1].Quantity
 	want := 2
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 	id2, _ := itemDB.FindIDByName("Phosphorescent Bile")
 	// fmt.Printf("ID: %d\n", id)
 	got2 := plug.Bids[id2].Quantity
 	want2 := 1
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got2, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got2, want2)
 	}
 	id3, _ := itemDB.FindIDByName("Cloth Cap")
 	// fmt.Printf("ID: %d\n", id)
 	got3 := plug.Bids[id3].Quantity
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got3, want2)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got3, want2)
+	}
+}
+
+func TestExportSpentDKPOnlyOnce(t *testing.T) {
+	Roster["Fakespender"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Fakespender", Class: "Necromancer"}}
+	path := filepath.Join(t.TempDir(), "ledger.csv")
+	oldStore := dkpStore
+	dkpStore = &FileStore{LedgerPath: path}
+	defer func() { dkpStore = oldStore }()
+	configuration.Bids.WriteSpentDKP = true
+	defer func() { configuration.Bids.WriteSpentDKP = false }()
+	exportSpentDKP([]Charge{{Name: "Fakespender", Cost: 55}, {Name: "Rot", Cost: 55}}, "Magi`Kot's Cloth Cap", "123456789")
+	exportSpentDKP([]Charge{{Name: "Fakespender", Cost: 55}, {Name: "Rot", Cost: 55}}, "Magi`Kot's Cloth Cap", "123456789") // closing again must not double charge
+	rows, err := dkpStore.LoadLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := len(rows)
+	want := 1
+	if got != want {
+		t.Fatalf("Got %d, want %d", got, want)
+	}
+	got2 := strings.Join(rows[0][3:7], ",")
+	want2 := time.Now().Format("01/02") + " BIDBOT_AUTO_FILL 123456789,Spent,MagiKots Cloth Cap,-55"
+	if got2 != want2 {
+		t.Errorf("Got %s, want %s", got2, want2)
+	}
+}
+
+func TestRevertSpentKey(t *testing.T) {
+	Roster["Fakespender"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Fakespender", Class: "Necromancer"}}
+	path := filepath.Join(t.TempDir(), "ledger.csv")
+	oldStore := dkpStore
+	dkpStore = &FileStore{LedgerPath: path}
+	defer func() { dkpStore = oldStore }()
+	configuration.Bids.WriteSpentDKP = true
+	defer func() { configuration.Bids.WriteSpentDKP = false }()
+	exportSpentDKP([]Charge{{Name: "Fakespender", Cost: 55}}, "Cloth Cap", "123456789")
+	summary := "[Mortimus] DKP written to ledger for Cloth Cap (BIDBOT_AUTO_FILL 123456789), react with x to revert"
+	match := spentKeyMatch.FindStringSubmatch(summary)
+	if match == nil || match[1] != "123456789" {
+		t.Fatalf("spentKeyMatch found %v in %q, want 123456789", match, summary)
+	}
+	refunds, err := revertSpentKey(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunds) != 1 || refunds[0][0] != "Fakespender" || refunds[0][6] != "55" {
+		t.Errorf("revertSpentKey() = %v, want one refund of 55 to Fakespender", refunds)
+	}
+	refunds, err = revertSpentKey(match[1]) // reverting again must not refund twice
+	if err != nil || len(refunds) != 0 {
+		t.Errorf("revertSpentKey() again = %v, %v, want nothing", refunds, err)
+	}
+	rows, err := dkpStore.LoadLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := len(rows)
+	want := 2
+	if got != want {
+		t.Errorf("Got %d ledger rows, want %d", got, want)
+	}
+}
+
+func TestBidAutoClose(t *testing.T) {
+	updateDKP = false
+	configuration.Bids.CloseAutomatically = true
+	defer func() { configuration.Bids.CloseAutomatically = false }()
+	plug := new(BidPlugin)
+	plug.Bids = make(map[int]*OpenBid)
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	start := time.Now().Add(-3 * time.Minute)
+	plug.Bids[id] = &OpenBid{
+		Item:     item,
+		Quantity: 1,
+		Duration: 2 * time.Minute,
+		Start:    start,
+		End:      start.Add(2 * time.Minute),
+		Bidders:  []*Bidder{},
+	}
+	var b bytes.Buffer
+	plug.Tick(time.Now(), &b)
+	if _, ok := plug.Bids[id]; ok {
+		t.Errorf("plug.Tick(now, &b) left bids open on %s after they ended", item.Name)
+	}
+}
+
+func TestBidCloseWarning(t *testing.T) {
+	configuration.Bids.CloseAutomatically = true
+	defer func() { configuration.Bids.CloseAutomatically = false }()
+	plug := new(BidPlugin)
+	plug.Bids = make(map[int]*OpenBid)
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	now := time.Now()
+	plug.Bids[id] = &OpenBid{
+		Item:     item,
+		Quantity: 1,
+		Duration: 2 * time.Minute,
+		Start:    now.Add(-100 * time.Second),
+		End:      now.Add(20 * time.Second),
+		Bidders:  []*Bidder{},
+	}
+	var b bytes.Buffer
+	plug.Tick(now, &b)
+	plug.Tick(now.Add(time.Second), &b) // only warn once
+	got := b.String()
+	want := fmt.Sprintf("> 30 seconds left to bid on %s (x1)\n", item.Name)
+	if got != want {
+		t.Errorf("plug.Tick(now, &b) = %q, want %q", got, want)
+	}
+	if _, ok := plug.Bids[id]; !ok {
+		t.Errorf("plug.Tick(now, &b) closed bids on %s early", item.Name)
+	}
+}
+
+func TestSplitBidTell(t *testing.T) {
+	tests := []struct {
+		tell string
+		want []string
+	}{
+		{"Cloth Cap 50", []string{"Cloth Cap 50"}},
+		{"Cloth Cap 50 | Sapphire of Capricious Magic 25", []string{"Cloth Cap 50", "Sapphire of Capricious Magic 25"}},
+		{"Cloth Cap 50, Sapphire of Capricious Magic 25", []string{"Cloth Cap 50", "Sapphire of Capricious Magic 25"}},
+		{"Cloth Cap 50, 2nd main", []string{"Cloth Cap 50, 2nd main"}},
+		{"Vial, Sealed 50", []string{"Vial, Sealed 50"}},
+	}
+	for _, tt := range tests {
+		got := splitBidTell(tt.tell)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("splitBidTell(%q) = %q, want %q", tt.tell, got, tt.want)
+		}
+	}
+}
+
+func TestBidAddMultipleItems(t *testing.T) {
+	plug := new(BidPlugin)
+	plug.BidNumber, _ = regexp.Compile(`\d+`)
+	plug.Bids = make(map[int]*OpenBid)
+	capID, _ := itemDB.FindIDByName("Cloth Cap")
+	capItem, _ := itemDB.GetItemByID(capID)
+	gemID, _ := itemDB.FindIDByName("Sapphire of Capricious Magic")
+	gemItem, _ := itemDB.GetItemByID(gemID)
+	plug.Bids[capID] = &OpenBid{Item: capItem, Quantity: 1}
+	plug.Bids[gemID] = &OpenBid{Item: gemItem, Quantity: 1}
+	add := new(everquest.EqLog)
+	add.Channel = "tell"
+	add.Msg = "Cloth Cap 50 | Sapphire of Capricious Magic 25"
+	add.Source = "Mortimus"
+	add.T = time.Now()
+	plug.HandleTell(add)
+	for id, want := range map[int]int{capID: 50, gemID: 25} {
+		pos := plug.Bids[id].FindBid("Mortimus")
+		if pos < 0 {
+			t.Errorf("HandleTell(%q) did not bid on %s", add.Msg, plug.Bids[id].Item.Name)
+			continue
+		}
+		bidder := plug.Bids[id].Bidders[pos]
+		if bidder.AttemptedBid != want {
+			t.Errorf("HandleTell(%q) bid %d on %s, want %d", add.Msg, bidder.AttemptedBid, plug.Bids[id].Item.Name, want)
+		}
+		if bidder.Tell != add.Msg {
+			t.Errorf("HandleTell(%q) kept tell %q, want the whole tell", add.Msg, bidder.Tell)
+		}
 	}
 }
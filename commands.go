@@ -0,0 +1,463 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	everquest "github.com/Mortimus/goEverquest"
+	"github.com/bwmarrin/discordgo"
+)
+
+const ephemeral = 1 << 6 // Only the member who used the command sees the response
+
+// parserBusy is the reply when the log parser could not run a command in time
+const parserBusy = "The bot is busy reading logs, try again in a moment"
+
+var commands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "dkp",
+		Description: "Show current DKP for a character",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "name",
+				Description: "Character name, defaults to your linked character",
+			},
+		},
+	},
+	{
+		Name:        "bids",
+		Description: "List items currently open for bids",
+	},
+	{
+		Name:        "bid",
+		Description: "Bid on an open item as your linked character",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "item",
+				Description: "Item name",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "amount",
+				Description: "DKP to bid, 0 cancels your bid",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "character",
+				Description: "Alt or second main to bid as, defaults to your linked main",
+			},
+		},
+	},
+	{
+		Name:        "history",
+		Description: "Show recent auctions a character bid on",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "name",
+				Description: "Character name, defaults to your linked character",
+			},
+		},
+	},
+	{
+		Name:        "search",
+		Description: "Search past auctions",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "What to look for",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Items a character won", Value: "won"},
+					{Name: "Average winning price of an item", Value: "price"},
+					{Name: "Who bid on an item but lost", Value: "losers"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "name",
+				Description: "Character or item name",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "days",
+				Description: "How many days back to look for won items, defaults to 30",
+			},
+		},
+	},
+	{
+		Name:        "link",
+		Description: "Get a code to link your discord account to your character",
+	},
+	{
+		Name:        "unlink",
+		Description: "Remove the link between your discord account and your character",
+	},
+}
+
+// registerCommands adds the slash commands to the guild, needs to run AFTER discord is opened
+func registerCommands() {
+	if !configuration.Discord.UseDiscord || !configuration.Discord.EnableCommands {
+		return
+	}
+	for _, cmd := range commands {
+		_, err := discord.ApplicationCommandCreate(discord.State.User.ID, configuration.Discord.GuildID, cmd)
+		if err != nil {
+			Err.Printf("Error registering command %s: %s", cmd.Name, err.Error())
+		}
+	}
+	discord.AddHandler(interactionCreate)
+}
+
+func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand || i.Member == nil {
+		return // we only register guild commands
+	}
+	known := false
+	for _, cmd := range commands {
+		if cmd.Name == i.Data.Name {
+			known = true
+		}
+	}
+	if !known {
+		return
+	}
+	// Answer within discord's 3 seconds, the reply follows once the parser and the ledger have been read
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionApplicationCommandResponseData{
+			Flags: ephemeral,
+		},
+	})
+	if err != nil {
+		Err.Printf("Error acknowledging /%s: %s", i.Data.Name, err.Error())
+		return
+	}
+	var reply string
+	switch i.Data.Name {
+	case "dkp":
+		reply = parserBusy
+		if name, ok := characterOption(i, "name"); ok {
+			reply = commandDKP(name)
+		}
+	case "bids":
+		reply = commandBids()
+	case "bid":
+		var item string
+		var amount int
+		character, ok := linkedCharacter(i.Member)
+		for _, opt := range i.Data.Options {
+			switch opt.Name {
+			case "item":
+				item = opt.StringValue()
+			case "amount":
+				amount = int(opt.IntValue())
+			case "character":
+				character, ok = ownedCharacter(i.Member, opt.StringValue())
+			}
+		}
+		reply = parserBusy
+		if ok {
+			reply = commandBid(character, item, amount)
+		}
+	case "history":
+		reply = parserBusy
+		if name, ok := characterOption(i, "name"); ok {
+			reply = commandHistory(name)
+		}
+	case "search":
+		var query, name string
+		var days int
+		for _, opt := range i.Data.Options {
+			switch opt.Name {
+			case "query":
+				query = opt.StringValue()
+			case "name":
+				name = opt.StringValue()
+			case "days":
+				days = int(opt.IntValue())
+			}
+		}
+		reply = commandSearch(query, name, days)
+	case "link":
+		reply = commandLink(i.Member.User.ID)
+	case "unlink":
+		reply = commandUnlink(i.Member.User.ID)
+	}
+	err = s.InteractionResponseEdit(s.State.User.ID, i.Interaction, &discordgo.WebhookEdit{Content: reply})
+	if err != nil {
+		Err.Printf("Error responding to /%s: %s", i.Data.Name, err.Error())
+	}
+}
+
+// characterOption returns the named option as a character name, falling back to the member's linked character,
+// false if the parser was too busy to look up the link
+func characterOption(i *discordgo.InteractionCreate, name string) (string, bool) {
+	for _, opt := range i.Data.Options {
+		if opt.Name == name {
+			return strings.Title(strings.ToLower(strings.TrimSpace(opt.StringValue()))), true
+		}
+	}
+	return linkedCharacter(i.Member)
+}
+
+// linkedCharacter finds the main a discord member has linked their account to, false if the parser was too busy to look
+func linkedCharacter(member *discordgo.Member) (string, bool) {
+	if member == nil || member.User == nil {
+		return "", true
+	}
+	var found string
+	ok := runOnParser(func() {
+		if plug := getLinkPlugin(); plug != nil {
+			found = plug.Main(member.User.ID)
+		}
+	})
+	return found, ok
+}
+
+// ownedCharacter returns the character if it is the member's linked main or one of its alts, false if the parser was too busy to look
+func ownedCharacter(member *discordgo.Member, character string) (string, bool) {
+	if member == nil || member.User == nil {
+		return "", true
+	}
+	character = strings.Title(strings.ToLower(strings.TrimSpace(character)))
+	var found string
+	ok := runOnParser(func() {
+		if plug := getLinkPlugin(); plug != nil && plug.Owns(member.User.ID, character) {
+			found = character
+		}
+	})
+	return found, ok
+}
+
+func commandLink(userID string) string {
+	var reply string
+	if !runOnParser(func() {
+		plug := getLinkPlugin()
+		if plug == nil {
+			reply = "Linking is not available"
+			return
+		}
+		code := plug.NewCode(userID)
+		reply = fmt.Sprintf("In game send `/tell %s link %s` within %s to link your character", getPlayerName(configuration.Everquest.LogPath), code, linkCodeTimeout)
+	}) {
+		return parserBusy
+	}
+	return reply
+}
+
+func commandUnlink(userID string) string {
+	var reply string
+	if !runOnParser(func() {
+		plug := getLinkPlugin()
+		if plug == nil || plug.Main(userID) == "" {
+			reply = "Your discord account is not linked"
+			return
+		}
+		reply = fmt.Sprintf("Your discord account is no longer linked to %s", plug.Links[userID])
+		plug.Unlink(userID)
+	}) {
+		return parserBusy
+	}
+	return reply
+}
+
+func commandDKP(name string) string {
+	if name == "" {
+		return "Which character? Your discord account is not linked to one"
+	}
+	var reply string
+	if !runOnParser(func() {
+		if _, ok := Roster[name]; !ok {
+			reply = fmt.Sprintf("%s is not in the guild roster", name)
+			return
+		}
+		if updateDKP {
+			updateRosterDKP()
+		}
+		holder := Roster[name]
+		main := getMain(&holder.GuildMember)
+		if main != name {
+			reply = fmt.Sprintf("%s (%s of %s) has %d DKP", name, DKPRankToString(holder.DKPRank), main, holder.DKP)
+			return
+		}
+		reply = fmt.Sprintf("%s (%s) has %d DKP", name, DKPRankToString(holder.DKPRank), holder.DKP)
+	}) {
+		return parserBusy
+	}
+	return reply
+}
+
+func commandBids() string {
+	var reply string
+	if !runOnParser(func() {
+		plug := getBidPlugin()
+		if plug == nil || len(plug.Bids) == 0 {
+			reply = "No items are open for bids"
+			return
+		}
+		var open []*OpenBid
+		for _, bid := range plug.Bids {
+			open = append(open, bid)
+		}
+		sort.Slice(open, func(i, j int) bool { return open[i].End.Before(open[j].End) })
+		reply = "```"
+		for _, bid := range open {
+			left := bid.End.Sub(getTime()).Round(time.Second)
+			if left < 0 {
+				left = 0
+			}
+			reply += fmt.Sprintf("%s (x%d) - %s left, %d bidder(s)\n", bid.Item.Name, bid.Quantity, left, len(bid.Bidders))
+		}
+		reply += "```"
+	}) {
+		return parserBusy
+	}
+	return reply
+}
+
+func commandBid(character string, item string, amount int) string {
+	if character == "" {
+		return "Your discord account is not linked to that character, use /link first"
+	}
+	if amount < 0 {
+		return "Bids cannot be negative"
+	}
+	msg := everquest.EqLog{
+		T:       getTime(),
+		Msg:     fmt.Sprintf("%s %d", strings.TrimSpace(item), amount),
+		Channel: "tell",
+		Source:  character,
+	}
+	var reply string
+	if !runOnParser(func() {
+		plug := getBidPlugin()
+		if plug == nil {
+			reply = "Bidding is not available"
+			return
+		}
+		investigation.addLog(msg) // Discord bids are investigated like tells
+		bus.PublishLog(&msg)
+		var replies []string
+		for _, part := range splitBidTell(msg.Msg) { // report each bid the way the tell was read
+			replies = append(replies, bidResult(plug, character, part))
+		}
+		reply = strings.Join(replies, "\n")
+	}) {
+		return parserBusy
+	}
+	return reply
+}
+
+// bidResult reports what a character has bid on the open bid one part of a tell matched
+func bidResult(plug *BidPlugin, character string, part string) string {
+	matches := plug.matchOpenBids(part)
+	if len(matches) == 0 {
+		return fmt.Sprintf("No open bids matched %s", part)
+	}
+	if len(matches) > 1 {
+		var names []string
+		for _, id := range matches {
+			names = append(names, plug.Bids[id].Item.Name)
+		}
+		sort.Strings(names)
+		return fmt.Sprintf("%s matched more than one item (%s), bid again with the full item name", part, strings.Join(names, ", "))
+	}
+	bid := plug.Bids[matches[0]]
+	pos := bid.FindBid(character)
+	if pos < 0 {
+		return fmt.Sprintf("%s has no bid on %s", character, bid.Item.Name)
+	}
+	return fmt.Sprintf("%s bid %d on %s", character, bid.Bidders[pos].AttemptedBid, bid.Item.Name)
+}
+
+func commandHistory(name string) string {
+	if name == "" {
+		return "Which character? Your discord account is not linked to one"
+	}
+	const maxHistory = 10
+	type auction struct {
+		ended time.Time
+		line  string
+	}
+	var auctions []auction
+	for _, id := range getArchiveList() {
+		arc, err := loadArchive(id)
+		if err != nil {
+			Err.Printf("Error reading archive %s: %s", id, err.Error())
+			continue
+		}
+		for _, bidder := range arc.Bidders {
+			if !strings.EqualFold(bidder.Player, name) && !strings.EqualFold(bidder.Main, name) {
+				continue
+			}
+			ended, _ := time.Parse(time.RFC822, arc.Ended)
+			result := "lost"
+			if bidder.WonOrTied {
+				result = fmt.Sprintf("won for %d", arc.WinningBid)
+			}
+			auctions = append(auctions, auction{ended: ended, line: fmt.Sprintf("%s %s: %s bid %d, %s\n", ended.Format("01/02"), arc.ItemName, bidder.Player, bidder.BidApplied, result)})
+		}
+	}
+	if len(auctions) == 0 {
+		return fmt.Sprintf("No auctions found for %s", name)
+	}
+	sort.Slice(auctions, func(i, j int) bool { return auctions[i].ended.After(auctions[j].ended) })
+	if len(auctions) > maxHistory {
+		auctions = auctions[:maxHistory]
+	}
+	reply := fmt.Sprintf("Recent auctions for %s\n```", name)
+	for _, a := range auctions {
+		reply += a.line
+	}
+	return reply + "```"
+}
+
+func commandSearch(query string, name string, days int) string {
+	var result string
+	var err error
+	if !runOnParser(func() {
+		result, err = searchArchive(archiveIndex, query, name, days)
+	}) {
+		return parserBusy
+	}
+	if err != nil {
+		return err.Error()
+	}
+	const maxReply = 1900 // discord messages are limited to 2000 characters
+	return "```\n" + cutLines(result, maxReply) + "```"
+}
+
+// cutLines shortens text to at most max bytes at a line break, marking that lines were dropped
+func cutLines(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := strings.LastIndex(text[:max], "\n")
+	if cut < 0 { // one long line, cut it at a character boundary instead
+		cut = max
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return text[:cut] + "\n...\n"
+}
+
+// getBidPlugin finds the loaded bid plugin
+func getBidPlugin() *BidPlugin {
+	for _, handler := range Handlers {
+		if plug, ok := handler.(*BidPlugin); ok {
+			return plug
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestCommandBidRequiresLink(t *testing.T) {
+	got := commandBid("", "Cloak of Flames", 50)
+	want := "Your discord account is not linked to that character, use /link first"
+	if got != want {
+		t.Errorf("commandBid() = %q, want %q", got, want)
+	}
+}
+
+func TestCommandBidNegative(t *testing.T) {
+	got := commandBid("Mortimus", "Cloak of Flames", -5)
+	want := "Bids cannot be negative"
+	if got != want {
+		t.Errorf("commandBid() = %q, want %q", got, want)
+	}
+}
+
+func TestRunOnParserAfterStop(t *testing.T) {
+	stopped := make(chan struct{})
+	close(stopped)
+	old := parserStopped
+	parserStopped = stopped
+	defer func() { parserStopped = old }()
+	ran := false
+	if runOnParser(func() { ran = true }) || ran {
+		t.Errorf("runOnParser() ran a task after the parser stopped")
+	}
+}
+
+func TestCutLines(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"short\n", 10, "short\n"},
+		{"first\nsecond\nthird\n", 15, "first\nsecond\n...\n"},
+		{"Ÿÿÿÿ", 5, "Ÿÿ\n...\n"},
+	}
+	for _, tt := range tests {
+		got := cutLines(tt.text, tt.max)
+		if got != tt.want {
+			t.Errorf("cutLines(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestBidResult(t *testing.T) {
+	plug := &BidPlugin{Bids: map[int]*OpenBid{
+		1: {Item: everquest.Item{Name: "Magi`Kot's Cloth Cap"}},
+		2: {Item: everquest.Item{Name: "Ring of Fire"}},
+		3: {Item: everquest.Item{Name: "Ring of Ice"}},
+	}}
+	plug.Bids[1].Bidders = []*Bidder{{Player: &DKPHolder{GuildMember: everquest.GuildMember{Name: "Mortimus"}}, AttemptedBid: 50}}
+	tests := []struct {
+		part string
+		want string
+	}{
+		{"magikots cloth cap 50", "Mortimus bid 50 on Magi`Kot's Cloth Cap"},
+		{"ring of fire 10", "Mortimus has no bid on Ring of Fire"},
+		{"ring of fice 10", "ring of fice 10 matched more than one item (Ring of Fire, Ring of Ice), bid again with the full item name"},
+		{"Breastplate of Nothing 10", "No open bids matched Breastplate of Nothing 10"},
+	}
+	for _, tt := range tests {
+		got := bidResult(plug, "Mortimus", tt.part)
+		if got != tt.want {
+			t.Errorf("bidResult(%q) = %q, want %q", tt.part, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -9,9 +12,15 @@ import (
 var discord *discordgo.Session
 
 func reactionAdd(s *discordgo.Session, m *discordgo.MessageReactionAdd) {
-	if m.Emoji.Name == configuration.Discord.InvestigationStartEmoji && getPrivReactions(s, m.MessageID, configuration.Discord.InvestigationStartEmoji) == configuration.Discord.InvestigationMinRequired && isArchive(m.MessageID) {
-		Info.Printf("Investigation message: %s", m.MessageID)
-		uploadArchive(m.MessageID)
+	if m.Emoji.Name == configuration.Discord.InvestigationStartEmoji && getPrivReactions(s, m.MessageID, configuration.Discord.InvestigationStartEmoji) == configuration.Discord.InvestigationMinRequired {
+		if archive, ok := archiveForMessage(m.MessageID); ok {
+			Info.Printf("Investigation message: %s", m.MessageID)
+			uploadArchive(archive)
+		}
+	}
+	if m.ChannelID == configuration.Discord.InvestigationChannelID && m.Emoji.Name == configuration.Discord.RevertSpentEmoji && m.UserID != s.State.User.ID && isPriviledged(s, m.UserID) {
+		Info.Printf("Reverting spent dkp message: %s", m.MessageID)
+		revertSpentDKP(m.MessageID)
 	}
 }
 
@@ -63,15 +72,71 @@ func isPriviledged(s *discordgo.Session, userID string) bool {
 	return false
 }
 
-// DiscordF provides a printf to a discord channel
-func DiscordF(channel string, format string, v ...interface{}) string {
-	if configuration.Discord.UseDiscord {
-		msg := fmt.Sprintf(format, v...)
-		dmsg, err := discord.ChannelMessageSend(channel, msg)
+// DiscordF provides a printf to a discord channel, queued behind the channel's other messages
+func DiscordF(channel string, format string, v ...interface{}) {
+	if !configuration.Discord.UseDiscord {
+		return
+	}
+	err := outbox.Enqueue(channel, fmt.Sprintf(format, v...), func(content string) error {
+		_, err := discord.ChannelMessageSend(channel, content)
+		return err
+	})
+	if err != nil {
+		Err.Printf("Failed to send message to %s: %s", channel, err.Error())
+	}
+}
+
+// DiscordMessageF is DiscordF that waits for the message to be sent and returns its id, empty if it was not
+func DiscordMessageF(channel string, format string, v ...interface{}) string {
+	if !configuration.Discord.UseDiscord {
+		return ""
+	}
+	ids := make(chan string, 1)
+	err := outbox.Send(channel, fmt.Sprintf(format, v...), func(content string) error {
+		dmsg, err := discord.ChannelMessageSend(channel, content)
 		if err != nil {
-			Err.Printf("Failed to send message to %s: %s", channel, err.Error())
+			return err
 		}
-		return dmsg.ID
+		ids <- dmsg.ID
+		return nil
+	})
+	if err != nil {
+		Err.Printf("Failed to send message to %s: %s", channel, err.Error())
+		return ""
+	}
+	return <-ids
+}
+
+// discordLong is DiscordF for messages that may be over discord's limit, they are split the way routes split output
+func discordLong(channel string, msg string) {
+	for _, chunk := range splitMessage(msg, maxMessageLength) {
+		DiscordF(channel, "%s", chunk)
+	}
+}
+
+// discordFile uploads a file to a discord channel behind the channel's queued messages
+func discordFile(channel string, name string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r) // the caller may close r before the upload is sent
+	if err != nil {
+		return err
 	}
-	return ""
+	return outbox.Call(channel, name, func() error {
+		_, err := discord.ChannelFileSend(channel, name, bytes.NewReader(data))
+		return err
+	})
+}
+
+// discordEmbed posts an embed to a discord channel behind the channel's queued messages
+func discordEmbed(channel string, embed *discordgo.MessageEmbed) error {
+	return outbox.Call(channel, embed.Title, func() error {
+		_, err := discord.ChannelMessageSendEmbed(channel, embed)
+		return err
+	})
+}
+
+// discordReaction reacts to a message behind the channel's queued messages
+func discordReaction(channel string, messageID string, emoji string) error {
+	return outbox.Call(channel, "reaction on "+messageID, func() error {
+		return discord.MessageReactionAdd(channel, messageID, emoji)
+	})
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dkpStore is where the DKP ledger, boss table and spell needs are kept
+var dkpStore DKPStore
+
+// DKPStore is a backend for the DKP ledger. Rows are returned as they appear in the sheet so the column settings in Sheets apply to every backend
+type DKPStore interface {
+	LoadLedger() ([][]string, error)                 // Raw DKP/Attendance rows including the header
+	LoadBosses() ([][]string, error)                 // Boss table rows including the header
+	LoadSpellNeeds(class string) ([][]string, error) // Spell sheet rows for a single class
+	AppendSpent(rows [][]string) error               // Adds rows to the end of the ledger
+}
+
+// newDKPStore builds the backend selected in the configuration
+func newDKPStore(backend string) (DKPStore, error) {
+	switch strings.ToLower(backend) {
+	case "", "sheets":
+		return &SheetsStore{}, nil
+	case "file":
+		return &FileStore{
+			LedgerPath:  configuration.Store.LedgerPath,
+			BossesPath:  configuration.Store.BossesPath,
+			SpellFolder: configuration.Store.SpellFolder,
+		}, nil
+	}
+	return nil, errors.New("unknown dkp store backend: " + backend)
+}
+
+// FileStore keeps the ledger in local csv files laid out the same as the google sheets, allowing the bot to run offline
+type FileStore struct {
+	LedgerPath  string
+	BossesPath  string
+	SpellFolder string
+}
+
+func (f *FileStore) LoadLedger() ([][]string, error) {
+	return readCSV(f.LedgerPath)
+}
+
+func (f *FileStore) LoadBosses() ([][]string, error) {
+	return readCSV(f.BossesPath)
+}
+
+func (f *FileStore) LoadSpellNeeds(class string) ([][]string, error) {
+	return readCSV(filepath.Join(f.SpellFolder, class+".csv"))
+}
+
+func (f *FileStore) AppendSpent(rows [][]string) error {
+	file, err := os.OpenFile(f.LedgerPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	err = writer.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func readCSV(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // sheets exports drop trailing empty cells
+	return reader.ReadAll()
+}
+
+// cell safely returns a column from a row, sheets leave off empty trailing cells
+func cell(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return row[col]
+}
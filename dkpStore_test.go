@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// restoreLedgerGlobals puts back the sheet columns and roster DKP that reading a test ledger overwrites
+func restoreLedgerGlobals(t *testing.T) {
+	oldSheets := configuration.Sheets
+	oldRoster := make(map[string]DKPHolder, len(Roster))
+	for name, member := range Roster {
+		oldRoster[name] = *member
+	}
+	oldStore := dkpStore
+	t.Cleanup(func() {
+		configuration.Sheets = oldSheets
+		for name := range Roster {
+			if _, ok := oldRoster[name]; !ok {
+				delete(Roster, name)
+			}
+		}
+		for name, member := range oldRoster {
+			if current, ok := Roster[name]; ok {
+				*current = member
+			} else {
+				member := member
+				Roster[name] = &member
+			}
+		}
+		dkpStore = oldStore
+	})
+}
+
+func TestFileStoreLedger(t *testing.T) {
+	restoreLedgerGlobals(t)
+	configuration.Sheets.RawSheetPlayerCol = 0
+	configuration.Sheets.RawSheetDateCol = 2
+	configuration.Sheets.RawSheetDKPCol = 6
+	configuration.Sheets.RawSheetAttendanceCol = 12
+	today := time.Now().Format("1/2/2006")
+	ledger := "Name,Day,Date,Raid,Type,Reason,Points,Alt or 2nd Main,Class,Guild Rank,Alt,DKP Rank,Attendance Calc,Level\n"
+	ledger += fmt.Sprintf("Fakeledger,Sun,%s,Raid,Earned,Boss,150,,Necromancer,Raider,No,Main,1.00,65\n", today)
+	ledger += fmt.Sprintf("Fakeledger,Sun,%s,Raid,Spent,Cloth Cap,-50,,Necromancer,Raider,No,Main,0.00,65\n", today)
+	path := filepath.Join(t.TempDir(), "ledger.csv")
+	err := ioutil.WriteFile(path, []byte(ledger), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Roster["Fakeledger"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Fakeledger", Class: "Necromancer"}}
+	dkpStore = &FileStore{LedgerPath: path}
+	updateRosterDKP()
+	got := Roster["Fakeledger"].DKP
+	want := 100
+	if got != want {
+		t.Errorf("Got %d, want %d", got, want)
+	}
+	got2 := Roster["Fakeledger"].Thirty
+	want2 := 1.0
+	if got2 != want2 {
+		t.Errorf("Got %f, want %f", got2, want2)
+	}
+}
+
+func TestFileStoreAppendSpent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.csv")
+	store := &FileStore{LedgerPath: path}
+	err := store.AppendSpent([][]string{{"Mortimus", "Sun", "1/2/2022", "01/02 BIDBOT_AUTO_FILL", "Spent", "Cloth Cap", "-10", ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := store.LoadLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := len(rows)
+	want := 1
+	if got != want {
+		t.Fatalf("Got %d, want %d", got, want)
+	}
+	got2 := cell(rows[0], 6)
+	want2 := "-10"
+	if got2 != want2 {
+		t.Errorf("Got %s, want %s", got2, want2)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// EventType identifies the kind of event published on the bus
+type EventType int
+
+const (
+	EventLoot EventType = iota
+	EventSlain
+	EventZoned
+	EventRoll
+	EventTell
+	EventSay
+	EventDumpWritten
+	EventLinkdead
+	EventBidOpened
+	EventBidClosed
+)
+
+// Event is something that happened, parsed once from the log or published by a plugin
+type Event interface {
+	Type() EventType
+}
+
+// LootEvent is a player looting an item from a corpse
+type LootEvent struct {
+	Log    *everquest.EqLog
+	Player string
+	Item   string
+	Corpse string
+}
+
+// SlainEvent is a mob being killed
+type SlainEvent struct {
+	Log    *everquest.EqLog
+	Target string
+	Slayer string
+}
+
+// ZonedEvent is the bot's character entering a zone
+type ZonedEvent struct {
+	Log  *everquest.EqLog
+	Zone string
+}
+
+// RollEvent is a /random result
+type RollEvent struct {
+	Log    *everquest.EqLog
+	Player string
+	Low    int
+	High   int
+	Result int
+}
+
+// TellEvent is a tell sent to the bot's character
+type TellEvent struct {
+	Log     *everquest.EqLog
+	Source  string
+	Message string
+}
+
+// SayEvent is anything said in /say range
+type SayEvent struct {
+	Log     *everquest.EqLog
+	Source  string
+	Message string
+}
+
+// DumpWrittenEvent is a raid or guild dump written by /outputfile
+type DumpWrittenEvent struct {
+	Log  *everquest.EqLog
+	File string // relative to the everquest base folder
+}
+
+// LinkdeadEvent is a player going linkdead
+type LinkdeadEvent struct {
+	Log    *everquest.EqLog
+	Player string
+}
+
+// BidOpenedEvent is published by the bid plugin when bids open on an item
+type BidOpenedEvent struct {
+	Bid *OpenBid
+}
+
+// BidClosedEvent is published by the bid plugin once the winners of a bid are final
+type BidClosedEvent struct {
+	Bid     *OpenBid
+	Winners []string // empty when the item rots
+}
+
+func (e LootEvent) Type() EventType        { return EventLoot }
+func (e SlainEvent) Type() EventType       { return EventSlain }
+func (e ZonedEvent) Type() EventType       { return EventZoned }
+func (e RollEvent) Type() EventType        { return EventRoll }
+func (e TellEvent) Type() EventType        { return EventTell }
+func (e SayEvent) Type() EventType         { return EventSay }
+func (e DumpWrittenEvent) Type() EventType { return EventDumpWritten }
+func (e LinkdeadEvent) Type() EventType    { return EventLinkdead }
+func (e BidOpenedEvent) Type() EventType   { return EventBidOpened }
+func (e BidClosedEvent) Type() EventType   { return EventBidClosed }
+
+// LogParser turns a log line into typed events, a nil regex skips that event
+type LogParser struct {
+	LootMatch *regexp.Regexp
+	SlayMatch *regexp.Regexp
+	RollMatch *regexp.Regexp
+}
+
+// logParser parses every log line once for the bus
+var logParser LogParser
+
+func init() {
+	logParser.LootMatch, _ = regexp.Compile(configuration.Everquest.RegexLoot)
+	logParser.SlayMatch, _ = regexp.Compile(configuration.Everquest.RegexSlay)
+	logParser.RollMatch, _ = regexp.Compile(configuration.Everquest.RegexRoll)
+}
+
+// selfName swaps "You" for the bot's character
+func selfName(player string) string {
+	if player == "You" {
+		return getPlayerName(configuration.Everquest.LogPath)
+	}
+	return player
+}
+
+// Parse returns the events in a log line, most lines have none
+func (lp *LogParser) Parse(msg *everquest.EqLog) []Event {
+	var events []Event
+	switch msg.Channel {
+	case "tell":
+		events = append(events, TellEvent{Log: msg, Source: msg.Source, Message: msg.Msg})
+	case "say":
+		events = append(events, SayEvent{Log: msg, Source: msg.Source, Message: msg.Msg})
+	case "system":
+		if lp.LootMatch != nil {
+			if match := lp.LootMatch.FindStringSubmatch(msg.Msg); len(match) > 3 {
+				events = append(events, LootEvent{Log: msg, Player: selfName(match[1]), Item: match[2], Corpse: match[3]})
+			}
+		}
+		if lp.SlayMatch != nil && strings.Contains(msg.Msg, "has been slain by ") { // A spectre has been slain by Mortimus!
+			if match := lp.SlayMatch.FindStringSubmatch(msg.Msg); len(match) > 2 {
+				events = append(events, SlainEvent{Log: msg, Target: match[1], Slayer: match[2]})
+			}
+		}
+		if lp.RollMatch != nil {
+			if match := lp.RollMatch.FindStringSubmatch(msg.Msg); len(match) > 4 {
+				low, _ := strconv.Atoi(match[2])
+				high, _ := strconv.Atoi(match[3])
+				result, _ := strconv.Atoi(match[4])
+				events = append(events, RollEvent{Log: msg, Player: selfName(match[1]), Low: low, High: high, Result: result})
+			}
+		}
+		if strings.Contains(msg.Msg, "Outputfile") && len(msg.Msg) > 21 {
+			events = append(events, DumpWrittenEvent{Log: msg, File: msg.Msg[21:]}) // Filename Outputfile sent data to
+		}
+		if strings.Contains(msg.Msg, "has gone Linkdead.") {
+			events = append(events, LinkdeadEvent{Log: msg, Player: strings.TrimSuffix(msg.Msg, " has gone Linkdead.")})
+		}
+		if strings.Contains(msg.Msg, "You have entered ") && !strings.Contains(msg.Msg, "function.") && !strings.Contains(msg.Msg, "Bind Affinity") { // You have entered Vex Thal. NOT You have entered an area where levitation effects do not function.
+			events = append(events, ZonedEvent{Log: msg, Zone: msg.Msg[17 : len(msg.Msg)-1]})
+		}
+	}
+	return events
+}
+
+// EventHandler is implemented by plugins that take typed events from the bus instead of raw log lines
+type EventHandler interface {
+	Subscriptions() []EventType
+	HandleEvent(event Event, out io.Writer)
+}
+
+func subscribed(handler EventHandler, eventType EventType) bool {
+	for _, subscription := range handler.Subscriptions() {
+		if subscription == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// EventBus delivers events to the plugins subscribed to them, only use it from the parser goroutine
+type EventBus struct {
+	queue       []Event
+	dispatching bool
+}
+
+var bus EventBus
+
+// Publish sends an event to every subscribed plugin, events published while handling one are sent after it, in order
+func (b *EventBus) Publish(event Event) {
+	b.queue = append(b.queue, event)
+	if b.dispatching {
+		return
+	}
+	b.dispatching = true
+	defer func() { b.dispatching = false }()
+	for len(b.queue) > 0 {
+		next := b.queue[0]
+		b.queue = b.queue[1:]
+		for _, handler := range Handlers {
+			if eHandler, ok := handler.(EventHandler); ok && subscribed(eHandler, next.Type()) {
+				eHandler.HandleEvent(next, getOutput(handler))
+			}
+		}
+	}
+}
+
+// PublishLog parses a log line once and publishes its events
+func (b *EventBus) PublishLog(msg *everquest.EqLog) {
+	for _, event := range logParser.Parse(msg) {
+		b.Publish(event)
+	}
+}
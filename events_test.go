@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func testParser() LogParser {
+	lootMatch, _ := regexp.Compile(`--(\w+) ha\w{1,2} looted a[n]? (.+) from (.+)['s corpse]?[ ]?\.--`)
+	slayMatch, _ := regexp.Compile(`(.+) has been slain by (\w+)!`)
+	rollMatch, _ := regexp.Compile(`\*\*A Magic Die is rolled by (\w+). It could have been any number from (\d+) to (\d+), but this time it turned up a (\d+).`)
+	return LogParser{LootMatch: lootMatch, SlayMatch: slayMatch, RollMatch: rollMatch}
+}
+
+// publishLog sends a log line to handler alone the way parseLogs does, parsing it with the test regexes and routing its output to out
+func publishLog(handler LogHandler, msg *everquest.EqLog, out io.Writer) {
+	savedHandlers, savedParser, savedRoutes := Handlers, logParser, routes
+	defer func() { Handlers, logParser, routes = savedHandlers, savedParser, savedRoutes }()
+	Handlers = []LogHandler{handler}
+	logParser = testParser()
+	routes = make(map[string]*RouteWriter)
+	for name, route := range savedRoutes {
+		routes[name] = route
+	}
+	routes[routeName(handler)] = &RouteWriter{Name: routeName(handler), Sinks: []io.Writer{out}}
+	if lHandler, ok := handler.(LineHandler); ok {
+		lHandler.Handle(msg, out)
+	}
+	bus.PublishLog(msg)
+}
+
+func TestParseEvents(t *testing.T) {
+	parser := testParser()
+	tests := []struct {
+		channel string
+		msg     string
+		want    Event
+	}{
+		{"system", "--Mortimus has looted a Cloth Cap from a glimmer drake's corpse.--", LootEvent{Player: "Mortimus", Item: "Cloth Cap", Corpse: "a glimmer drake's corpse"}},
+		{"system", "Kraksmaal Fir`Dethsin has been slain by Mortimus!", SlainEvent{Target: "Kraksmaal Fir`Dethsin", Slayer: "Mortimus"}},
+		{"system", "**A Magic Die is rolled by Mortimus. It could have been any number from 0 to 1000, but this time it turned up a 523.", RollEvent{Player: "Mortimus", Low: 0, High: 1000, Result: 523}},
+		{"system", "Outputfile Complete: RaidRoster_aradune-20210417-205952.txt", DumpWrittenEvent{File: "RaidRoster_aradune-20210417-205952.txt"}},
+		{"system", "Mortimus has gone Linkdead.", LinkdeadEvent{Player: "Mortimus"}},
+		{"system", "You have entered Vex Thal.", ZonedEvent{Zone: "Vex Thal"}},
+		{"tell", "Cloth Cap 10", TellEvent{Source: "Mortimus", Message: "Cloth Cap 10"}},
+		{"say", "Hail, Seer Mal Nae`Shi", SayEvent{Source: "Mortimus", Message: "Hail, Seer Mal Nae`Shi"}},
+	}
+	for _, tt := range tests {
+		msg := &everquest.EqLog{Channel: tt.channel, Msg: tt.msg, Source: "Mortimus", T: time.Now()}
+		events := parser.Parse(msg)
+		if len(events) != 1 {
+			t.Errorf("Parse(%q) = %d events, want 1", tt.msg, len(events))
+			continue
+		}
+		got := withoutLog(events[0])
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoresLevitationZone(t *testing.T) {
+	parser := testParser()
+	msg := &everquest.EqLog{Channel: "system", Msg: "You have entered an area where levitation effects do not function."}
+	got := len(parser.Parse(msg))
+	want := 0
+	if got != want {
+		t.Errorf("len(Parse(msg)) = %d, want %d", got, want)
+	}
+}
+
+func TestParseSkipsMissingRegex(t *testing.T) {
+	parser := LogParser{}
+	msg := &everquest.EqLog{Channel: "system", Msg: "--Mortimus has looted a Cloth Cap from a glimmer drake's corpse.--"}
+	got := len(parser.Parse(msg))
+	want := 0
+	if got != want {
+		t.Errorf("len(Parse(msg)) = %d, want %d", got, want)
+	}
+}
+
+// withoutLog drops the log line so parsed events can be compared
+func withoutLog(event Event) Event {
+	switch e := event.(type) {
+	case LootEvent:
+		e.Log = nil
+		return e
+	case SlainEvent:
+		e.Log = nil
+		return e
+	case RollEvent:
+		e.Log = nil
+		return e
+	case DumpWrittenEvent:
+		e.Log = nil
+		return e
+	case LinkdeadEvent:
+		e.Log = nil
+		return e
+	case ZonedEvent:
+		e.Log = nil
+		return e
+	case TellEvent:
+		e.Log = nil
+		return e
+	case SayEvent:
+		e.Log = nil
+		return e
+	}
+	return event
+}
+
+// recordingPlugin keeps every event it is sent, publishing a BidClosed for every BidOpened
+type recordingPlugin struct {
+	Plugin
+	Events []string
+}
+
+func (p *recordingPlugin) Info(out io.Writer) {}
+func (p *recordingPlugin) OutputChannel() int { return p.Output }
+func (p *recordingPlugin) Subscriptions() []EventType {
+	return []EventType{EventBidOpened, EventBidClosed, EventZoned}
+}
+func (p *recordingPlugin) HandleEvent(event Event, out io.Writer) {
+	switch e := event.(type) {
+	case BidOpenedEvent:
+		p.Events = append(p.Events, "opened "+e.Bid.Item.Name)
+		bus.Publish(BidClosedEvent{Bid: e.Bid})
+		bus.Publish(ZonedEvent{Zone: "Vex Thal"})
+	case BidClosedEvent:
+		p.Events = append(p.Events, fmt.Sprintf("closed %s %d", e.Bid.Item.Name, len(e.Winners)))
+	case ZonedEvent:
+		p.Events = append(p.Events, "zoned "+e.Zone)
+	}
+}
+
+func TestBusPublishesNestedEventsInOrder(t *testing.T) {
+	plug := &recordingPlugin{Plugin: Plugin{Output: TESTOUT}}
+	saved := Handlers
+	Handlers = []LogHandler{plug}
+	defer func() { Handlers = saved }()
+	bid := &OpenBid{Item: everquest.Item{Name: "Cloth Cap"}}
+	bus.Publish(BidOpenedEvent{Bid: bid})
+	bus.Publish(LootEvent{Item: "Cloth Cap"}) // not subscribed
+	got := plug.Events
+	want := []string{"opened Cloth Cap", "closed Cloth Cap 0", "zoned Vex Thal"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plug.Events = %v, want %v", got, want)
+	}
+}
+
+func TestLootNeedsLootedAfterBidClosed(t *testing.T) {
+	plug := new(LootPlugin)
+	needsLooted = nil
+	bid := &OpenBid{Item: everquest.Item{Name: "Cloth Cap"}}
+	plug.HandleEvent(BidClosedEvent{Bid: bid}, io.Discard)
+	plug.HandleEvent(BidClosedEvent{Bid: bid, Winners: []string{"Mortimus"}}, io.Discard)
+	got := needsLooted
+	want := []string{"Cloth Cap"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("needsLooted = %v, want %v", got, want)
+	}
+}
@@ -3,17 +3,13 @@ package main
 import (
 	"fmt"
 	"io"
-	"regexp"
 	"strings"
-
-	everquest "github.com/Mortimus/goEverquest"
 )
 
 var flagPiece map[string]interface{}
 
 type FlagPlugin struct {
 	Plugin
-	LootMatch *regexp.Regexp
 }
 
 func init() {
@@ -22,32 +18,29 @@ func init() {
 	plug.Author = "Mortimus"
 	plug.Version = "1.0.0"
 	plug.Output = FLAGOUT
-	Handlers = append(Handlers, plug)
+	registerPlugin("flags", plug)
 
-	plug.LootMatch, _ = regexp.Compile(configuration.Everquest.RegexLoot)
 	seedFlagPieces()
 }
 
-// Handle for ParsePlugin sends a message if a parse was pasted to the parse channel
-func (p *FlagPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if msg.Channel == "say" && strings.Contains(msg.Msg, "Hail, ") {
+func (p *FlagPlugin) Subscriptions() []EventType {
+	return []EventType{EventSay, EventLoot}
+}
+
+func (p *FlagPlugin) HandleEvent(event Event, out io.Writer) {
+	switch e := event.(type) {
+	case SayEvent:
+		if !strings.Contains(e.Message, "Hail, ") {
+			return
+		}
 		for _, flaggiver := range configuration.Everquest.FlagGiver {
-			if strings.Contains(msg.Msg, flaggiver) {
-				fmt.Fprintf(out, "%s got the flag from %s\n", msg.Source, currentZone)
+			if strings.Contains(e.Message, flaggiver) {
+				fmt.Fprintf(out, "%s got the flag from %s\n", e.Source, currentZone)
 			}
 		}
-	}
-	if msg.Channel == "system" {
-		match := p.LootMatch.FindStringSubmatch(msg.Msg)
-		if len(match) > 0 {
-			player := match[1]
-			if player == "You" {
-				player = getPlayerName(configuration.Everquest.LogPath)
-			}
-			loot := match[2]
-			if loot != "" && isFlagPiece(loot) {
-				fmt.Fprintf(out, "%s got the %s flag from %s\n", player, loot, currentZone)
-			}
+	case LootEvent:
+		if e.Item != "" && isFlagPiece(e.Item) {
+			fmt.Fprintf(out, "%s got the %s flag from %s\n", e.Player, e.Item, currentZone)
 		}
 	}
 }
@@ -17,10 +17,10 @@ func TestFlag(t *testing.T) {
 	msg.T = time.Now()
 	currentZone = "TEST"
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Mortimus got the flag from TEST\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// stopWords are dropped when comparing item names, people leave them out of tells
+var stopWords = map[string]bool{"of": true, "the": true, "a": true, "an": true}
+
+// normalizeItemName lowercases, strips punctuation like Magi`Kot's, expands abbreviations and drops stop words
+func normalizeItemName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '`':
+			// Magi`Kot's and MagiKots should match
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	var words []string
+	for _, word := range strings.Fields(b.String()) {
+		if long, ok := configuration.Bids.Abbreviations[word]; ok {
+			words = append(words, strings.Fields(normalizeWords(long))...)
+			continue
+		}
+		if stopWords[word] {
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// normalizeWords cleans an abbreviation's expansion without expanding it again
+func normalizeWords(s string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		if !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// levenshtein is the number of single character edits to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// closestDistance compares an item name against every run of words in a tell that could be the name
+func closestDistance(text, name string) int {
+	textWords := strings.Fields(text)
+	nameWords := len(strings.Fields(name))
+	best := levenshtein(text, name)
+	for size := nameWords - 1; size <= nameWords+1; size++ {
+		if size < 1 {
+			continue
+		}
+		for start := 0; start+size <= len(textWords); start++ {
+			if d := levenshtein(strings.Join(textWords[start:start+size], " "), name); d < best {
+				best = d
+			}
+		}
+	}
+	return best
+}
+
+// minFuzzyLength is the shortest normalized name that may match with typos, shorter names like cap are too close to ordinary words
+const minFuzzyLength = 5
+
+// fuzzyThreshold is how many typos are allowed for a normalized name
+func fuzzyThreshold(name string) int {
+	if len(name) < minFuzzyLength {
+		return 0
+	}
+	if configuration.Bids.FuzzyDistance > 0 {
+		return configuration.Bids.FuzzyDistance
+	}
+	threshold := len(name) / 5
+	if threshold < 1 {
+		threshold = 1
+	}
+	return threshold
+}
+
+// lastNumber is the bid amount in a tell, the last number with only words like dkp after it
+var lastNumber = regexp.MustCompile(`\d+\D*$`)
+
+// splitBidAmount splits a tell into the text before the bid amount and the amount onwards
+func splitBidAmount(message string) (string, string) {
+	loc := lastNumber.FindStringIndex(message)
+	if loc == nil {
+		return message, ""
+	}
+	return message[:loc[0]], message[loc[0]:]
+}
+
+// matchOpenBids finds the open bids a tell could be for, more than one result is ambiguous
+func (p *BidPlugin) matchOpenBids(message string) []int {
+	// Exact names win, the longest if one name is inside another
+	var exact []int
+	for id, bid := range p.Bids {
+		if strings.Contains(message, bid.Item.Name) {
+			exact = append(exact, id)
+		}
+	}
+	if len(exact) > 0 {
+		sort.Slice(exact, func(i, j int) bool { return len(p.Bids[exact[i]].Item.Name) > len(p.Bids[exact[j]].Item.Name) })
+		return exact[:1]
+	}
+	// Compare the text naming the item without case, punctuation or stop words
+	named, amount := splitBidAmount(strings.Replace(message, "2nd", "", -1))
+	if strings.TrimSpace(named) == "" { // amount came first, like 50 cloth cap
+		named = strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return ' '
+			}
+			return r
+		}, amount)
+	}
+	text := normalizeItemName(named)
+	var contained []int
+	for id, bid := range p.Bids {
+		name := normalizeItemName(bid.Item.Name)
+		if name != "" && strings.Contains(" "+text+" ", " "+name+" ") {
+			contained = append(contained, id)
+		}
+	}
+	if len(contained) > 0 {
+		sort.Slice(contained, func(i, j int) bool {
+			return len(p.Bids[contained[i]].Item.Name) > len(p.Bids[contained[j]].Item.Name)
+		})
+		return contained[:1]
+	}
+	// Closest name within the typo threshold, ties are ambiguous
+	best := -1
+	var matches []int
+	for id, bid := range p.Bids {
+		name := normalizeItemName(bid.Item.Name)
+		distance := closestDistance(text, name)
+		if distance > fuzzyThreshold(name) {
+			continue
+		}
+		switch {
+		case best == -1 || distance < best:
+			best = distance
+			matches = []int{id}
+		case distance == best:
+			matches = append(matches, id)
+		}
+	}
+	sort.Ints(matches)
+	return matches
+}
+
+// reportAmbiguousBid rejects a tell that matched more than one open bid and leaves it for an officer to look at
+func reportAmbiguousBid(p *BidPlugin, msg *everquest.EqLog, matches []int) {
+	var names []string
+	for _, id := range matches {
+		names = append(names, p.Bids[id].Item.Name)
+	}
+	Warn.Printf("Ambiguous bid from %s: %q could be %s", msg.Source, msg.Msg, strings.Join(names, ", "))
+	DiscordF(configuration.Discord.InvestigationChannelID, "[%s] Ambiguous bid from %s was not taken\n```\n%s\n```\n> Could be: %s", getPlayerName(configuration.Everquest.LogPath), msg.Source, msg.Msg, strings.Join(names, ", "))
+	queueTell(msg.Source, fmt.Sprintf("Your bid matched more than one item (%s), send it again with the full item name", strings.Join(names, ", ")))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestNormalizeItemName(t *testing.T) {
+	configuration.Bids.Abbreviations = map[string]string{"bp": "Breastplate"}
+	defer func() { configuration.Bids.Abbreviations = nil }()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Magi`Kot's Cloth Cap", "magikots cloth cap"},
+		{"Cloak of the Wind", "cloak wind"},
+		{"  Spiked   Seahorse-Hide Belt ", "spiked seahorse hide belt"},
+		{"Dragonscale BP", "dragonscale breastplate"},
+	}
+	for _, tt := range tests {
+		if got := normalizeItemName(tt.name); got != tt.want {
+			t.Errorf("normalizeItemName(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"cap", "", 3},
+		{"kitten", "sitting", 3},
+		{"cloak wind", "clok wind", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchOpenBids(t *testing.T) {
+	p := &BidPlugin{Bids: map[int]*OpenBid{
+		1: {Item: everquest.Item{Name: "Magi`Kot's Cloth Cap"}},
+		2: {Item: everquest.Item{Name: "Cloak of the Wind"}},
+		3: {Item: everquest.Item{Name: "Cloak of the Wind Spirit"}},
+		4: {Item: everquest.Item{Name: "Ring of Fire"}},
+		5: {Item: everquest.Item{Name: "Ring of Ice"}},
+		6: {Item: everquest.Item{Name: "Rune of Frost 2"}},
+		7: {Item: everquest.Item{Name: "Orb"}},
+	}}
+	tests := []struct {
+		msg  string
+		want []int
+	}{
+		{"Magi`Kot's Cloth Cap 50", []int{1}},
+		{"magikots cloth cap 50", []int{1}},
+		{"Magi'Kot's Cloth Cap 2nd 50", []int{1}},
+		{"Cloak of the Wind Spirit 20", []int{3}},
+		{"cloak of wind 20", []int{2}},
+		{"clok of the wind 20", []int{2}},
+		{"ring of fira 10", []int{4}},
+		{"ring of fice 10", []int{4, 5}},
+		{"Breastplate of Nothing 10", nil},
+		{"rune of frost 2 30", []int{6}},
+		{"orc 50", nil},
+		{"orb 50", []int{7}},
+	}
+	for _, tt := range tests {
+		got := p.matchOpenBids(tt.msg)
+		if len(got) != len(tt.want) {
+			t.Errorf("matchOpenBids(%q) = %v; want %v", tt.msg, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("matchOpenBids(%q) = %v; want %v", tt.msg, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSplitBidAmount(t *testing.T) {
+	tests := []struct {
+		msg        string
+		wantName   string
+		wantAmount string
+	}{
+		{"rune of frost 2 30", "rune of frost 2 ", "30"},
+		{"cloth cap 50 dkp", "cloth cap ", "50 dkp"},
+		{"50 cloth cap", "", "50 cloth cap"},
+		{"cloth cap", "cloth cap", ""},
+	}
+	for _, tt := range tests {
+		name, amount := splitBidAmount(tt.msg)
+		if name != tt.wantName || amount != tt.wantAmount {
+			t.Errorf("splitBidAmount(%q) = %q, %q; want %q, %q", tt.msg, name, amount, tt.wantName, tt.wantAmount)
+		}
+	}
+}
+
+func TestFuzzyThresholdShortNames(t *testing.T) {
+	if got := fuzzyThreshold("cap"); got != 0 {
+		t.Errorf("fuzzyThreshold(%q) = %d; want 0", "cap", got)
+	}
+	if got := fuzzyThreshold("cloak wind"); got != 2 {
+		t.Errorf("fuzzyThreshold(%q) = %d; want 2", "cloak wind", got)
+	}
+}
@@ -18,13 +18,16 @@ func init() {
 	plug.Author = "Mortimus"
 	plug.Version = "1.0.0"
 	plug.Output = INVESTIGATEOUT
-	Handlers = append(Handlers, plug)
+	registerPlugin("guild", plug)
 }
 
-// Handle for ParsePlugin sends a message if a parse was pasted to the parse channel
-func (p *GuildPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if msg.Channel == "system" && strings.Contains(msg.Msg, "Outputfile") && strings.Contains(msg.Msg, configuration.Everquest.GuildName) {
-		outputName := msg.Msg[21:] // Filename Outputfile sent data to
+func (p *GuildPlugin) Subscriptions() []EventType {
+	return []EventType{EventDumpWritten}
+}
+
+func (p *GuildPlugin) HandleEvent(event Event, out io.Writer) {
+	if e, ok := event.(DumpWrittenEvent); ok && strings.Contains(e.File, configuration.Everquest.GuildName) {
+		outputName := e.File
 
 		guild := new(everquest.Guild)
 		err := guild.LoadFromPath(configuration.Everquest.BaseFolder+"/"+outputName, Err)
@@ -37,7 +40,8 @@ func (p *GuildPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
 			if err != nil {
 				fmt.Fprintf(out, "Error finding Guild Dump: %s\n", outputName)
 			} else {
-				discord.ChannelFileSend(configuration.Discord.RaidDumpChannelID, outputName, guildFile)
+				sendFile("dumps", outputName, guildFile)
+				guildFile.Close()
 			}
 			updateGuildRoster(guild) // Fix github issue?
 			// exportGuild(guild)
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	embedColorClean      = 0x2ecc71
+	embedColorSuspicious = 0xe74c3c
+	embedFieldLimit      = 1024
+)
+
+// loadArchive reads a closed bid's investigation from the archive folder
+func loadArchive(id string) (BidInvestigation, error) {
+	var arc BidInvestigation
+	data, err := ioutil.ReadFile("archive/" + id + ".json")
+	if err != nil {
+		return arc, err
+	}
+	err = json.Unmarshal(data, &arc)
+	return arc, err
+}
+
+// IsBidder is true for anyone that bid or cancelled a bid in the archive
+func (arc *BidInvestigation) IsBidder(player string) bool {
+	for _, bidders := range [][]InvestigationBidder{arc.Bidders, arc.Cancelled} {
+		for _, bidder := range bidders {
+			if bidder.Player == player {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LateTells are tells from bidders that arrived outside the bid window
+func (arc *BidInvestigation) LateTells() []InvestigationLog {
+	var late []InvestigationLog
+	for _, log := range arc.Logs {
+		if !log.InBidWindow && arc.IsBidder(log.Player) {
+			late = append(late, log)
+		}
+	}
+	return late
+}
+
+// NonEquippers are bidders with a bid applied on an item their class cannot use
+func (arc *BidInvestigation) NonEquippers() []InvestigationBidder {
+	var bidders []InvestigationBidder
+	for _, bidder := range arc.Bidders {
+		if !bidder.CanEquip && bidder.BidApplied > 0 {
+			bidders = append(bidders, bidder)
+		}
+	}
+	return bidders
+}
+
+// Suspicious is true when an officer should look closer at the archive
+func (arc *BidInvestigation) Suspicious() bool {
+	for _, finding := range arc.Findings {
+		if severity, _ := parseSeverity(finding.Severity); severity >= SeverityWarning {
+			return true
+		}
+	}
+	return len(arc.LateTells()) > 0 || len(arc.NonEquippers()) > 0
+}
+
+// embedField keeps a field under discord's length limit
+func embedField(name string, lines []string) *discordgo.MessageEmbedField {
+	value := strings.Join(lines, "\n")
+	if value == "" {
+		value = "None"
+	}
+	value = strings.TrimSuffix(cutLines(value, embedFieldLimit-len("\n...\n")), "\n")
+	return &discordgo.MessageEmbedField{Name: name, Value: value}
+}
+
+// investigationEmbed summarizes an archive for the investigation channel
+func investigationEmbed(arc BidInvestigation) *discordgo.MessageEmbed {
+	var winners, bidders, late, nonEquippers, findings []string
+	for _, bidder := range arc.Bidders {
+		if bidder.WonOrTied {
+			winners = append(winners, fmt.Sprintf("%s (%s) paid %d", bidder.Player, bidder.Main, bidder.Price))
+		}
+		line := fmt.Sprintf("%s - %s, bid %d counted %d", bidder.Player, bidder.DKPRank, bidder.BidAttempted, bidder.BidApplied)
+		if bidder.Proxy != "" {
+			line += ", by " + bidder.Proxy
+		}
+		bidders = append(bidders, line)
+	}
+	for _, bidder := range arc.Cancelled {
+		bidders = append(bidders, fmt.Sprintf("%s - %s, cancelled", bidder.Player, bidder.DKPRank))
+	}
+	for _, log := range arc.LateTells() {
+		late = append(late, fmt.Sprintf("%s %s: %s", log.Received, log.Player, log.Message))
+	}
+	for _, bidder := range arc.NonEquippers() {
+		nonEquippers = append(nonEquippers, fmt.Sprintf("%s bid %d", bidder.Player, bidder.BidApplied))
+	}
+	for _, finding := range arc.Findings {
+		findings = append(findings, fmt.Sprintf("[%s] %s: %s", finding.Severity, finding.Rule, finding.Detail))
+	}
+	color := embedColorClean
+	if arc.Suspicious() {
+		color = embedColorSuspicious
+	}
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Investigation: %s (x%d)", arc.ItemName, arc.Quantity),
+		Description: fmt.Sprintf("%s to %s, winning bid %d", arc.Started, arc.Ended, arc.WinningBid),
+		Color:       color,
+		Fields: []*discordgo.MessageEmbedField{
+			embedField("Winners", winners),
+			embedField("Bidders", bidders),
+			embedField("Tells outside the bid window", late),
+			embedField("Cannot equip", nonEquippers),
+			embedField("Audit findings", findings),
+		},
+	}
+}
+
+var investigationTemplate = template.Must(template.New("investigation").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Investigation: {{.ItemName}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+tr.suspicious { background: #f8d7da; }
+tr.winner { background: #d4edda; }
+</style>
+</head>
+<body>
+<h1>{{.ItemName}} (x{{.Quantity}})</h1>
+<p>{{.Started}} to {{.Ended}}, winning bid {{.WinningBid}}, pricing {{if .Pricing}}{{.Pricing}}{{else}}second-price{{end}}</p>
+{{if .Findings}}<h2>Audit findings</h2>
+<table>
+<tr><th>Severity</th><th>Rule</th><th>Player</th><th>Detail</th></tr>
+{{range .Findings}}<tr{{if ne .Severity "info"}} class="suspicious"{{end}}><td>{{.Severity}}</td><td>{{.Rule}}</td><td>{{.Player}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>
+{{end}}<h2>Bidders</h2>
+<table>
+<tr><th>Player</th><th>Main</th><th>Rank</th><th>DKP</th><th>Bid</th><th>Counted</th><th>Price</th><th>Can equip</th><th>Proxy</th><th>Rule</th><th>Message</th></tr>
+{{range .Bidders}}<tr{{if and (not .CanEquip) (gt .BidApplied 0)}} class="suspicious"{{else if .WonOrTied}} class="winner"{{end}}><td>{{.Player}}</td><td>{{.Main}}</td><td>{{.DKPRank}}</td><td>{{.DKP}}</td><td>{{.BidAttempted}}</td><td>{{.BidApplied}}</td><td>{{if .WonOrTied}}{{.Price}}{{end}}</td><td>{{.CanEquip}}</td><td>{{.Proxy}}</td><td>{{.Rule}}</td><td>{{.Message}}</td></tr>
+{{end}}{{range .Cancelled}}<tr><td>{{.Player}}</td><td>{{.Main}}</td><td>{{.DKPRank}}</td><td>{{.DKP}}</td><td colspan="7">cancelled</td></tr>
+{{end}}</table>
+<h2>Timeline</h2>
+<table>
+<tr><th>Time</th><th>Player</th><th>Event</th><th>Amount</th><th>Message</th></tr>
+{{range $bidder := .Bidders}}{{range .Events}}<tr><td>{{.Time.Format "15:04:05"}}</td><td>{{$bidder.Player}}</td><td>{{.Type}}</td><td>{{.Amount}}</td><td>{{.Message}}</td></tr>
+{{end}}{{end}}{{range $bidder := .Cancelled}}{{range .Events}}<tr><td>{{.Time.Format "15:04:05"}}</td><td>{{$bidder.Player}}</td><td>{{.Type}}</td><td>{{.Amount}}</td><td>{{.Message}}</td></tr>
+{{end}}{{end}}</table>
+<h2>Logs</h2>
+<table>
+<tr><th>Received</th><th>Player</th><th>Main</th><th>Rank</th><th>In window</th><th>Message</th></tr>
+{{range .Logs}}<tr{{if and (not .InBidWindow) ($.IsBidder .Player)}} class="suspicious"{{end}}><td>{{.Received}}</td><td>{{.Player}}</td><td>{{.Main}}</td><td>{{.DKPRank}}</td><td>{{.InBidWindow}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// renderInvestigationHTML builds a standalone report of an archive, suspicious rows are highlighted
+func renderInvestigationHTML(arc BidInvestigation) ([]byte, error) {
+	var buf bytes.Buffer
+	err := investigationTemplate.Execute(&buf, &arc)
+	return buf.Bytes(), err
+}
+
+// uploadInvestigationReport posts the summary embed and, if enabled, the HTML report for an archive
+func uploadInvestigationReport(id string) {
+	arc, err := loadArchive(id)
+	if err != nil {
+		Err.Printf("Error reading archive %s for the report: %s", id, err.Error())
+		return
+	}
+	err = discordEmbed(configuration.Discord.InvestigationChannelID, investigationEmbed(arc))
+	if err != nil {
+		Err.Printf("Error sending investigation embed: %s", err.Error())
+	}
+	if !configuration.Discord.InvestigationHTML {
+		return
+	}
+	report, err := renderInvestigationHTML(arc)
+	if err != nil {
+		Err.Printf("Error rendering investigation report: %s", err.Error())
+		return
+	}
+	err = discordFile(configuration.Discord.InvestigationChannelID, id+".html", bytes.NewReader(report))
+	if err != nil {
+		Err.Printf("Error sending investigation report: %s", err.Error())
+	}
+}
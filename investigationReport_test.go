@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testArchive() BidInvestigation {
+	return BidInvestigation{
+		ItemName:   "Cloth Cap",
+		Quantity:   1,
+		WinningBid: 55,
+		Bidders: []InvestigationBidder{
+			{Player: "Winner", Main: "Winner", DKPRank: "Main", BidAttempted: 100, BidApplied: 100, CanEquip: true, WonOrTied: true, Price: 55},
+			{Player: "Wizard", Main: "Wizard", DKPRank: "Main", BidAttempted: 50, BidApplied: 50, CanEquip: false},
+		},
+		Logs: []InvestigationLog{
+			{Player: "Winner", Message: "Cloth Cap 100", InBidWindow: true},
+			{Player: "Wizard", Message: "Cloth Cap 60", InBidWindow: false},
+			{Player: "Bystander", Message: "grats", InBidWindow: false},
+		},
+	}
+}
+
+func TestInvestigationSuspicious(t *testing.T) {
+	arc := testArchive()
+	if got := len(arc.LateTells()); got != 1 {
+		t.Errorf("len(LateTells()) = %d, want 1", got)
+	}
+	if got := len(arc.NonEquippers()); got != 1 {
+		t.Errorf("len(NonEquippers()) = %d, want 1", got)
+	}
+	embed := investigationEmbed(arc)
+	if embed.Color != embedColorSuspicious {
+		t.Errorf("investigationEmbed().Color = %x, want %x", embed.Color, embedColorSuspicious)
+	}
+	if !strings.Contains(embed.Fields[0].Value, "Winner (Winner) paid 55") {
+		t.Errorf("investigationEmbed() winners = %q, want the winner and price", embed.Fields[0].Value)
+	}
+	arc.Bidders = arc.Bidders[:1]
+	if arc.Suspicious() {
+		t.Errorf("Suspicious() = true, want false without late tells or non equippers")
+	}
+}
+
+func TestRenderInvestigationHTML(t *testing.T) {
+	arc := testArchive()
+	arc.Logs[0].Message = "<script>"
+	report, err := renderInvestigationHTML(arc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(report)
+	if strings.Contains(got, "<script>") {
+		t.Errorf("renderInvestigationHTML() did not escape log messages")
+	}
+	if !strings.Contains(got, `class="suspicious"`) {
+		t.Errorf("renderInvestigationHTML() did not highlight suspicious rows")
+	}
+}
+
+func TestEmbedField(t *testing.T) {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "Bidder - Main, bid 100 counted 100 ✓")
+	}
+	field := embedField("Bidders", lines)
+	if len(field.Value) > embedFieldLimit {
+		t.Errorf("embedField() value is %d bytes, want at most %d", len(field.Value), embedFieldLimit)
+	}
+	if !strings.HasSuffix(field.Value, "✓\n...") {
+		t.Errorf("embedField() = %q, want it cut after a whole line", field.Value[len(field.Value)-20:])
+	}
+}
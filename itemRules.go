@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// ItemRule changes how bidding works on an item, or on every item from a zone
+type ItemRule struct {
+	ItemID     int    `comment:"Item ID the rule applies to, 0 to match by name or zone"`
+	Item       string `comment:"Item name the rule applies to"`
+	Zone       string `comment:"Zone the rule applies to when no item rule matches"`
+	MinimumBid int    `comment:"Minimum bid on the item, 0 uses the Bids setting"`
+	MaxBid     int    `comment:"Max bid on the item, 0 uses the Bids setting"`
+	ClassLock  bool   `comment:"Reject bids from classes that cannot use the item instead of auto investigating them"`
+	FreeRoll   bool   `comment:"No DKP is charged, bidders roll with classes that can use the item before those that cannot"`
+}
+
+// findItemRule returns the rule for an item, matching by ID, then name, then zone
+func findItemRule(item everquest.Item, zone string) *ItemRule {
+	var byName, byZone *ItemRule
+	for i := range configuration.ItemRules {
+		rule := &configuration.ItemRules[i]
+		switch {
+		case rule.ItemID != 0 && rule.ItemID == item.ID:
+			return rule
+		case rule.ItemID == 0 && rule.Item != "" && strings.EqualFold(rule.Item, item.Name):
+			if byName == nil {
+				byName = rule
+			}
+		case rule.ItemID == 0 && rule.Item == "" && rule.Zone != "" && strings.EqualFold(rule.Zone, zone):
+			if byZone == nil {
+				byZone = rule
+			}
+		}
+	}
+	if byName != nil {
+		return byName
+	}
+	return byZone
+}
+
+// String describes the rule for the loot message
+func (r *ItemRule) String() string {
+	var parts []string
+	if r.FreeRoll {
+		parts = append(parts, "free roll, no DKP")
+	}
+	if r.MinimumBid > 0 && !r.FreeRoll {
+		parts = append(parts, "minimum bid "+strconv.Itoa(r.MinimumBid))
+	}
+	if r.MaxBid > 0 && !r.FreeRoll {
+		parts = append(parts, "max bid "+strconv.Itoa(r.MaxBid))
+	}
+	if r.ClassLock {
+		parts = append(parts, "usable classes only")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (b *OpenBid) minimumBid() int {
+	if b.ItemRule != nil && b.ItemRule.MinimumBid > 0 {
+		return b.ItemRule.MinimumBid
+	}
+	return configuration.Bids.MinimumBid
+}
+
+func (b *OpenBid) maxBid() int {
+	if b.ItemRule != nil && b.ItemRule.MaxBid > 0 {
+		return b.ItemRule.MaxBid
+	}
+	return configuration.Bids.MaxBid
+}
+
+func (b *OpenBid) isFreeRoll() bool {
+	return b.ItemRule != nil && b.ItemRule.FreeRoll
+}
+
+func (b *OpenBid) isClassLocked() bool {
+	return b.ItemRule != nil && b.ItemRule.ClassLock
+}
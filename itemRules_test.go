@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestFindItemRule(t *testing.T) {
+	configuration.ItemRules = []ItemRule{
+		{Zone: "Plane of Fear", MinimumBid: 20},
+		{Item: "Cloth Cap", MinimumBid: 50},
+		{ItemID: 1001, FreeRoll: true},
+	}
+	defer func() { configuration.ItemRules = nil }()
+	tests := []struct {
+		item everquest.Item
+		zone string
+		want int
+	}{
+		{everquest.Item{ID: 1001, Name: "Cloth Cap"}, "Plane of Fear", 0},
+		{everquest.Item{ID: 1002, Name: "Cloth Cap"}, "Plane of Fear", 50},
+		{everquest.Item{ID: 1003, Name: "Leather Cap"}, "Plane of Fear", 20},
+	}
+	for _, tt := range tests {
+		rule := findItemRule(tt.item, tt.zone)
+		if rule == nil {
+			t.Errorf("findItemRule(%s) = nil", tt.item.Name)
+			continue
+		}
+		if rule.MinimumBid != tt.want {
+			t.Errorf("findItemRule(%d %s).MinimumBid = %d; want %d", tt.item.ID, tt.item.Name, rule.MinimumBid, tt.want)
+		}
+	}
+	if rule := findItemRule(everquest.Item{ID: 1004, Name: "Leather Cap"}, "Nexus"); rule != nil {
+		t.Errorf("findItemRule() matched %#v; want no rule", rule)
+	}
+}
+
+func TestClassLockRejectsBid(t *testing.T) {
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	if len(item.GetClasses()) == 0 {
+		t.Skip("Cloth Cap is usable by every class")
+	}
+	bid := &OpenBid{Item: item, Quantity: 1, ItemRule: &ItemRule{ClassLock: true}}
+	player := DKPHolder{GuildMember: everquest.GuildMember{Name: "Lockedout", Class: "Unknown"}}
+	bid.AddBid(player, 50, everquest.EqLog{})
+	if got := bid.FindBid("Lockedout"); got != -1 {
+		t.Errorf("class locked item accepted a bid from a class that cannot use it")
+	}
+}
+
+func TestFreeRollNeedBeforeGreed(t *testing.T) {
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	if len(item.GetClasses()) == 0 {
+		t.Skip("Cloth Cap is usable by every class")
+	}
+	Roster["Freeneed"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Freeneed", Class: item.GetClasses()[0]}, DKPRank: MAIN}
+	Roster["Freegreed"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Freegreed", Class: "Unknown"}, DKPRank: MAIN}
+	bid := &OpenBid{Item: item, Quantity: 1, ItemRule: &ItemRule{FreeRoll: true}, Bidders: []*Bidder{
+		{Player: Roster["Freegreed"], AttemptedBid: 500},
+		{Player: Roster["Freeneed"], AttemptedBid: 10},
+	}}
+	bid.ApplyDKP()
+	bid.SortBids()
+	if got := bid.Bidders[0].Player.Name; got != "Freeneed" {
+		t.Errorf("free roll sorted %s first; want Freeneed", got)
+	}
+}
+
+func TestFreeRollIgnoresTier(t *testing.T) {
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	if len(item.GetClasses()) == 0 {
+		t.Skip("Cloth Cap is usable by every class")
+	}
+	Roster["Freealtneed"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Freealtneed", Class: item.GetClasses()[0]}, DKPRank: ALT}
+	Roster["Freemainneed"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Freemainneed", Class: item.GetClasses()[0]}, DKPRank: MAIN}
+	Roster["Freemaingreed"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Freemaingreed", Class: "Unknown"}, DKPRank: MAIN}
+	bid := &OpenBid{Item: item, Quantity: 1, ItemRule: &ItemRule{FreeRoll: true}, Bidders: []*Bidder{
+		{Player: Roster["Freemaingreed"], AttemptedBid: 10},
+		{Player: Roster["Freealtneed"], AttemptedBid: 10},
+		{Player: Roster["Freemainneed"], AttemptedBid: 10},
+	}}
+	bid.ApplyDKP()
+	bid.SortBids()
+	if got := bid.Bidders[2].Player.Name; got != "Freemaingreed" {
+		t.Errorf("free roll sorted %s last; want Freemaingreed", got)
+	}
+	ties := bid.CheckTiesAndApplyWinners()
+	for _, name := range []string{"Freealtneed", "Freemainneed"} {
+		if _, ok := ties[name]; !ok {
+			t.Errorf("CheckTiesAndApplyWinners() = %v; want %s to roll", ties, name)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	linkCodeLength  = 6
+	linkCodeLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O or 1/I to misread in game
+	linkCodeTimeout = 10 * time.Minute
+)
+
+// LinkPlugin verifies discord accounts against the characters they play by having the member send a code as an in game tell
+type LinkPlugin struct {
+	Plugin
+	LinkMatch *regexp.Regexp
+	Pending   map[string]*PendingLink // link code to the account that requested it
+	Links     map[string]string       // discord user ID to the verified character
+}
+
+// PendingLink is a link code that has not been sent in game yet
+type PendingLink struct {
+	UserID  string
+	Expires time.Time
+}
+
+func init() {
+	plug := new(LinkPlugin)
+	plug.Name = "Discord account linking"
+	plug.Author = "Mortimus"
+	plug.Version = "1.0.0"
+	plug.Output = STDOUT
+	plug.LinkMatch, _ = regexp.Compile(`^(?i)link\s+([a-z0-9]+)$`)
+	plug.Pending = make(map[string]*PendingLink)
+	plug.Links = make(map[string]string)
+	err := plug.loadLinks()
+	if err != nil {
+		fmt.Printf("Error loading discord links: %s", err.Error())
+	}
+	registerPlugin("link", plug)
+}
+
+func (p *LinkPlugin) Subscriptions() []EventType {
+	return []EventType{EventTell}
+}
+
+// HandleEvent for LinkPlugin verifies link codes sent as tells
+func (p *LinkPlugin) HandleEvent(event Event, out io.Writer) {
+	msg, ok := event.(TellEvent)
+	if !ok {
+		return
+	}
+	match := p.LinkMatch.FindStringSubmatch(strings.TrimSpace(msg.Message))
+	if match == nil {
+		return
+	}
+	code := strings.ToUpper(match[1])
+	pending, ok := p.Pending[code]
+	if !ok || getTime().After(pending.Expires) {
+		delete(p.Pending, code)
+		fmt.Fprintf(out, "%s sent an unknown or expired link code %s\n", msg.Source, code)
+		return
+	}
+	if _, ok := Roster[msg.Source]; !ok {
+		fmt.Fprintf(out, "%s tried to link but is not in the guild roster\n", msg.Source)
+		dmUser(pending.UserID, fmt.Sprintf("%s is not in the guild roster, cannot link your account", msg.Source))
+		return
+	}
+	delete(p.Pending, code)
+	p.Links[pending.UserID] = msg.Source
+	p.saveLinks()
+	fmt.Fprintf(out, "%s linked their discord account\n", msg.Source)
+	dmUser(pending.UserID, fmt.Sprintf("Your discord account is now linked to %s (%s)", msg.Source, strings.Join(p.Characters(pending.UserID), ", ")))
+}
+
+// NewCode creates a link code for a discord account, any older code for that account is dropped
+func (p *LinkPlugin) NewCode(userID string) string {
+	for code, pending := range p.Pending {
+		if pending.UserID == userID || getTime().After(pending.Expires) {
+			delete(p.Pending, code)
+		}
+	}
+	buf := make([]byte, linkCodeLength)
+	_, err := rand.Read(buf)
+	if err != nil {
+		Err.Printf("Error generating link code: %s", err.Error())
+	}
+	code := make([]byte, linkCodeLength)
+	for i, b := range buf {
+		code[i] = linkCodeLetters[int(b)%len(linkCodeLetters)]
+	}
+	p.Pending[string(code)] = &PendingLink{
+		UserID:  userID,
+		Expires: getTime().Add(linkCodeTimeout),
+	}
+	return string(code)
+}
+
+// Unlink removes a discord account's link
+func (p *LinkPlugin) Unlink(userID string) {
+	delete(p.Links, userID)
+	p.saveLinks()
+}
+
+// Main returns the main of the character a discord account is linked to
+func (p *LinkPlugin) Main(userID string) string {
+	name, ok := p.Links[userID]
+	if !ok {
+		return ""
+	}
+	holder, ok := Roster[name]
+	if !ok {
+		return "" // no longer in the guild
+	}
+	return getMain(&holder.GuildMember)
+}
+
+// Characters returns the linked main followed by every alt and second main that belongs to it
+func (p *LinkPlugin) Characters(userID string) []string {
+	main := p.Main(userID)
+	if main == "" {
+		return nil
+	}
+	var alts []string
+	for name, holder := range Roster {
+		if name != main && getMain(&holder.GuildMember) == main {
+			alts = append(alts, name)
+		}
+	}
+	sort.Strings(alts)
+	return append([]string{main}, alts...)
+}
+
+// Owns checks if a character is the linked main or one of its alts
+func (p *LinkPlugin) Owns(userID string, character string) bool {
+	for _, name := range p.Characters(userID) {
+		if strings.EqualFold(name, character) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *LinkPlugin) saveLinks() {
+	if configuration.Discord.LinkPath == "" {
+		return
+	}
+	file, err := json.MarshalIndent(p.Links, "", " ")
+	if err != nil {
+		Err.Printf("Error converting discord links to JSON: %s", err.Error())
+		return
+	}
+	err = ioutil.WriteFile(configuration.Discord.LinkPath, file, 0644)
+	if err != nil {
+		Err.Printf("Error writing discord links: %s", err.Error())
+	}
+}
+
+func (p *LinkPlugin) loadLinks() error {
+	if configuration.Discord.LinkPath == "" {
+		return nil
+	}
+	file, err := ioutil.ReadFile(configuration.Discord.LinkPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // nobody has linked yet
+		}
+		return err
+	}
+	return json.Unmarshal(file, &p.Links)
+}
+
+func (p *LinkPlugin) Info(out io.Writer) {
+	fmt.Fprintf(out, "---------------\n")
+	fmt.Fprintf(out, "Name: %s\n", p.Name)
+	fmt.Fprintf(out, "Author: %s\n", p.Author)
+	fmt.Fprintf(out, "Version: %s\n", p.Version)
+	fmt.Fprintf(out, "---------------\n")
+}
+
+func (p *LinkPlugin) OutputChannel() int {
+	return p.Output
+}
+
+// getLinkPlugin finds the loaded link plugin
+func getLinkPlugin() *LinkPlugin {
+	for _, handler := range Handlers {
+		if plug, ok := handler.(*LinkPlugin); ok {
+			return plug
+		}
+	}
+	return nil
+}
+
+// dmUser sends a direct message to a discord user
+func dmUser(userID string, message string) {
+	if !configuration.Discord.UseDiscord {
+		return
+	}
+	err := outbox.Call("dm:"+userID, "direct message", func() error {
+		channel, err := discord.UserChannelCreate(userID)
+		if err != nil {
+			return err
+		}
+		_, err = discord.ChannelMessageSend(channel.ID, message)
+		return err
+	})
+	if err != nil {
+		Err.Printf("Error sending DM to %s: %s", userID, err.Error())
+	}
+}
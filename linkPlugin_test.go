@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// newTestLinkPlugin is an empty link plugin using the registered plugin's tell pattern
+func newTestLinkPlugin(t *testing.T) *LinkPlugin {
+	t.Helper()
+	registered := getLinkPlugin()
+	if registered == nil {
+		t.Fatal("link plugin is not registered")
+	}
+	plug := new(LinkPlugin)
+	plug.LinkMatch = registered.LinkMatch
+	plug.Pending = make(map[string]*PendingLink)
+	plug.Links = make(map[string]string)
+	return plug
+}
+
+func TestLinkPluginVerify(t *testing.T) {
+	Roster["Linkmain"] = &DKPHolder{
+		GuildMember: everquest.GuildMember{Name: "Linkmain", Rank: "Raider"},
+	}
+	Roster["Linkalt"] = &DKPHolder{
+		GuildMember: everquest.GuildMember{Name: "Linkalt", Rank: "Raider", Alt: true, PublicNote: "Linkmain's Alt"},
+	}
+	plug := newTestLinkPlugin(t)
+	code := plug.NewCode("1234")
+	msg := everquest.EqLog{
+		T:       time.Now(),
+		Msg:     "link " + code,
+		Channel: "tell",
+		Source:  "Linkalt",
+	}
+	var b bytes.Buffer
+	publishLog(plug, &msg, &b)
+	got := plug.Main("1234")
+	want := "Linkmain"
+	if got != want {
+		t.Errorf("plug.Main(\"1234\") = %s; want %s", got, want)
+	}
+	if !plug.Owns("1234", "Linkalt") {
+		t.Errorf("plug.Owns(\"1234\", \"Linkalt\") = false; want true")
+	}
+	if _, ok := plug.Pending[code]; ok {
+		t.Errorf("link code %s was not used up", code)
+	}
+}
+
+func TestLinkPluginWrongCode(t *testing.T) {
+	plug := newTestLinkPlugin(t)
+	plug.NewCode("1234")
+	msg := everquest.EqLog{
+		T:       time.Now(),
+		Msg:     "link AAAAAA",
+		Channel: "tell",
+		Source:  "Mortimus",
+	}
+	var b bytes.Buffer
+	publishLog(plug, &msg, &b)
+	got := plug.Main("1234")
+	if got != "" {
+		t.Errorf("plug.Main(\"1234\") = %s; want no link", got)
+	}
+}
@@ -3,9 +3,6 @@ package main
 import (
 	"fmt"
 	"io"
-	"strings"
-
-	everquest "github.com/Mortimus/goEverquest"
 )
 
 type LinkdeadPlugin Plugin
@@ -16,13 +13,16 @@ func init() {
 	ldplug.Author = "Mortimus"
 	ldplug.Version = "1.0.0"
 	ldplug.Output = RAIDOUT
-	Handlers = append(Handlers, ldplug)
+	registerPlugin("linkdead", ldplug)
+}
+
+func (p *LinkdeadPlugin) Subscriptions() []EventType {
+	return []EventType{EventLinkdead}
 }
 
-// Handle for LinkdeadPlugin sends a message if it detects a player has gone linkdead.
-func (p *LinkdeadPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if msg.Channel == "system" && strings.Contains(msg.Msg, "has gone Linkdead.") {
-		fmt.Fprintf(out, "%s\n", msg.Msg)
+func (p *LinkdeadPlugin) HandleEvent(event Event, out io.Writer) {
+	if e, ok := event.(LinkdeadEvent); ok {
+		fmt.Fprintf(out, "%s\n", e.Log.Msg)
 	}
 }
 
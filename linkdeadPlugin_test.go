@@ -16,10 +16,10 @@ func TestLinkdead(t *testing.T) {
 	msg.Source = "Mortimus"
 	msg.T = time.Now()
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Mortimus has gone Linkdead.\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
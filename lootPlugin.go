@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"io"
-	"regexp"
 	"strings"
 
 	everquest "github.com/Mortimus/goEverquest"
@@ -14,7 +13,6 @@ var needsLooted []string
 // type LootPlugin Plugin
 type LootPlugin struct {
 	Plugin
-	LootMatch *regexp.Regexp
 }
 
 func init() {
@@ -23,35 +21,33 @@ func init() {
 	plug.Author = "Mortimus"
 	plug.Version = "1.0.0"
 	plug.Output = SPELLOUT
-	Handlers = append(Handlers, plug)
+	registerPlugin("loot", plug)
 	seedInferredItems()
+}
 
-	plug.LootMatch, _ = regexp.Compile(configuration.Everquest.RegexLoot)
+func (p *LootPlugin) Subscriptions() []EventType {
+	return []EventType{EventLoot, EventBidClosed}
 }
 
-// Handle for ParsePlugin sends a message if a parse was pasted to the parse channel
-func (p *LootPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if msg.Channel == "system" {
-		match := p.LootMatch.FindStringSubmatch(msg.Msg)
-		if len(match) > 0 {
-			player := match[1]
-			if player == "You" {
-				player = getPlayerName(configuration.Everquest.LogPath)
-			}
-			loot := match[2]
-			corpse := match[3]
-			// fmt.Printf("%#+v\n", loot)
-			class := "Unknown"
-			if _, ok := Roster[player]; ok {
-				class = Roster[player].Class
-			}
-			if loot != "" && strings.Contains(loot, "Spell: ") || strings.Contains(loot, "Ancient: ") || isSpellProvider(loot) || isAwardedLoot(loot) {
-				// Lookup spell name, and what players need it
-				loot = inferLoot(class, loot) // Check if item results in a class specific item, and replace it here.
-				id, _ := itemDB.FindIDByName(loot)
-				item, _ := itemDB.GetItemByID(id)
-				fmt.Fprintf(out, "> %s (%s) looted %s from %s\n```%s```\n", player, class, item.Name, corpse, getItemDesc(item)) // TODO: Make this a sexy print with item stats
-			}
+func (p *LootPlugin) HandleEvent(event Event, out io.Writer) {
+	switch e := event.(type) {
+	case LootEvent:
+		player, loot := e.Player, e.Item
+		// fmt.Printf("%#+v\n", loot)
+		class := "Unknown"
+		if _, ok := Roster[player]; ok {
+			class = Roster[player].Class
+		}
+		if loot != "" && strings.Contains(loot, "Spell: ") || strings.Contains(loot, "Ancient: ") || isSpellProvider(loot) || isAwardedLoot(loot) {
+			// Lookup spell name, and what players need it
+			loot = inferLoot(class, loot) // Check if item results in a class specific item, and replace it here.
+			id, _ := itemDB.FindIDByName(loot)
+			item, _ := itemDB.GetItemByID(id)
+			fmt.Fprintf(out, "> %s (%s) looted %s from %s\n```%s```\n", player, class, item.Name, e.Corpse, getItemDesc(item)) // TODO: Make this a sexy print with item stats
+		}
+	case BidClosedEvent:
+		if len(e.Winners) > 0 { // don't require looted for rotted items
+			needsLooted = append(needsLooted, e.Bid.Item.Name)
 		}
 	}
 }
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxMessageLength   = 1000 // discord allows 2000, keep posts readable
+	defaultOutboxSize  = 100
+	outboxMaxAttempts  = 5
+	outboxBackoff      = time.Second
+	outboxMaxBackoff   = 30 * time.Second
+	codeFence          = "```"
+	outboxMetricsEvery = time.Hour
+	maxFenceLanguage   = 20
+	outboxWait         = 10 * time.Second // longest a caller waits on a message it needs the id of
+	outboxFlushTimeout = 10 * time.Second // longest shutdown waits for queued messages
+)
+
+var errOutboxFull = errors.New("outbound message queue is full")
+var errOutboxTimeout = errors.New("timed out waiting on the outbound message queue")
+
+// outbox sends discord messages off the log parsing goroutine
+var outbox *Outbox
+
+// OutboxMetrics counts what happened to queued messages since startup
+type OutboxMetrics struct {
+	Queued  int
+	Sent    int
+	Retried int
+	Dropped int // queue was full
+	Failed  int // gave up after retrying
+	Waiting map[string]int
+}
+
+func (m OutboxMetrics) String() string {
+	var waiting int
+	for _, n := range m.Waiting {
+		waiting += n
+	}
+	return fmt.Sprintf("queued %d, sent %d, retried %d, dropped %d, failed %d, waiting %d", m.Queued, m.Sent, m.Retried, m.Dropped, m.Failed, waiting)
+}
+
+type outboxMessage struct {
+	content string
+	send    func(content string) error
+	result  chan error // gets the final error when the sender waits on it
+}
+
+// Outbox keeps a bounded, ordered queue per destination and sends each with retries on its own goroutine
+type Outbox struct {
+	size    int
+	sleep   func(time.Duration)
+	mu      sync.Mutex
+	queues  map[string]chan outboxMessage
+	pending int // queued or being sent
+	metrics OutboxMetrics
+}
+
+func newOutbox(size int) *Outbox {
+	if size <= 0 {
+		size = defaultOutboxSize
+	}
+	return &Outbox{size: size, sleep: time.Sleep, queues: make(map[string]chan outboxMessage)}
+}
+
+// Enqueue adds a message to the destination's queue without waiting, it is dropped if the queue is full
+func (o *Outbox) Enqueue(destination string, content string, send func(content string) error) error {
+	return o.enqueue(destination, outboxMessage{content: content, send: send})
+}
+
+// Call queues any other request for the destination, like a file upload or an edit, behind what is already waiting
+func (o *Outbox) Call(destination string, what string, call func() error) error {
+	return o.Enqueue(destination, what, func(string) error { return call() })
+}
+
+// Send queues a message and waits until it is sent or given up on, for callers that need what discord sends back
+func (o *Outbox) Send(destination string, content string, send func(content string) error) error {
+	result := make(chan error, 1)
+	err := o.enqueue(destination, outboxMessage{content: content, send: send, result: result})
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-result:
+		return err
+	case <-time.After(outboxWait):
+		return errOutboxTimeout
+	}
+}
+
+func (o *Outbox) enqueue(destination string, msg outboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	queue, ok := o.queues[destination]
+	if !ok {
+		queue = make(chan outboxMessage, o.size)
+		o.queues[destination] = queue
+		go o.drain(destination, queue)
+	}
+	select {
+	case queue <- msg:
+		o.metrics.Queued++
+		o.pending++
+		return nil
+	default:
+		o.metrics.Dropped++
+		Warn.Printf("Dropping message to %s, %d messages already waiting", destination, o.size)
+		return errOutboxFull
+	}
+}
+
+func (o *Outbox) drain(destination string, queue chan outboxMessage) {
+	for msg := range queue {
+		err := o.deliver(destination, msg)
+		if msg.result != nil {
+			msg.result <- err
+		}
+		o.mu.Lock()
+		o.pending--
+		o.mu.Unlock()
+	}
+}
+
+// deliver sends one message, retrying rate limits, server and network errors with backoff
+func (o *Outbox) deliver(destination string, msg outboxMessage) error {
+	for attempt := 1; ; attempt++ {
+		err := msg.send(msg.content)
+		if err == nil {
+			o.count(func(m *OutboxMetrics) { m.Sent++ })
+			return nil
+		}
+		delay, retry := retryDelay(err, attempt)
+		if !retry || attempt >= outboxMaxAttempts {
+			o.count(func(m *OutboxMetrics) { m.Failed++ })
+			Err.Printf("Giving up on message to %s after %d attempt(s): %s", destination, attempt, err.Error())
+			return err
+		}
+		o.count(func(m *OutboxMetrics) { m.Retried++ })
+		o.sleep(delay)
+	}
+}
+
+// Flush waits for every queued message to be sent or given up on, false if the timeout ran out first
+func (o *Outbox) Flush(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		o.mu.Lock()
+		pending := o.pending
+		o.mu.Unlock()
+		if pending == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			Warn.Printf("Shutting down with %d outbound message(s) unsent", pending)
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (o *Outbox) count(update func(m *OutboxMetrics)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	update(&o.metrics)
+}
+
+// Metrics returns the counts so far and how many messages wait per destination
+func (o *Outbox) Metrics() OutboxMetrics {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	metrics := o.metrics
+	metrics.Waiting = make(map[string]int)
+	for destination, queue := range o.queues {
+		metrics.Waiting[destination] = len(queue)
+	}
+	return metrics
+}
+
+// logMetrics writes the outbox metrics to the info log every so often
+func (o *Outbox) logMetrics(every time.Duration) {
+	for range time.Tick(every) {
+		Info.Printf("Outbound discord messages: %s", o.Metrics())
+	}
+}
+
+// retryDelay decides if a failed send is worth another try and how long to wait first
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	backoff := outboxBackoff << uint(attempt-1)
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	var status int
+	var retryAfter time.Duration
+	var restErr *discordgo.RESTError
+	var hookErr *webhookError
+	switch {
+	case errors.As(err, &restErr):
+		if restErr.Response == nil {
+			return backoff, true
+		}
+		status = restErr.Response.StatusCode
+		retryAfter = parseRetryAfter(restErr.Response.Header.Get("Retry-After"))
+	case errors.As(err, &hookErr):
+		status = hookErr.StatusCode
+		retryAfter = hookErr.RetryAfter
+	default:
+		return backoff, true // network errors
+	}
+	switch {
+	case status == http.StatusTooManyRequests && retryAfter > 0:
+		return retryAfter, true
+	case status == http.StatusTooManyRequests || status >= 500:
+		return backoff, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter reads discord's Retry-After header, in seconds
+func parseRetryAfter(header string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(header), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// splitMessage breaks a message into chunks of at most limit bytes, preferring line breaks.
+// A code block cut in two is closed at the end of one chunk and reopened in the next.
+func splitMessage(msg string, limit int) []string {
+	var chunks []string
+	var reopen string // fence to start the next chunk with when the last one ended inside a code block
+	for len(msg) > 0 {
+		if len(reopen)+len(msg) <= limit {
+			chunks = append(chunks, reopen+msg)
+			break
+		}
+		room := limit - len(reopen) - len(codeFence)
+		cut := strings.LastIndex(msg[:room], "\n") + 1
+		if cut <= 0 {
+			cut = room
+			for cut > 0 && !utf8.RuneStart(msg[cut]) {
+				cut--
+			}
+		}
+		chunk := reopen + msg[:cut]
+		msg = msg[cut:]
+		reopen = openFence(chunk)
+		if reopen != "" {
+			chunk += codeFence
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// openFence returns the fence, with its language, of a code block left open at the end of text
+func openFence(text string) string {
+	var open string
+	for {
+		i := strings.Index(text, codeFence)
+		if i < 0 {
+			return open
+		}
+		text = text[i+len(codeFence):]
+		if open != "" {
+			open = ""
+			continue
+		}
+		open = codeFence
+		if line := strings.Index(text, "\n"); line > 0 && line <= maxFenceLanguage && !strings.ContainsAny(text[:line], " `") {
+			open += text[:line] // language like ```ini
+		}
+		if open != codeFence || strings.HasPrefix(text, "\n") {
+			open += "\n"
+		}
+	}
+}
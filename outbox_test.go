@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSplitMessageShort(t *testing.T) {
+	got := splitMessage("> Mortimus provided a parse", 1000)
+	want := []string{"> Mortimus provided a parse"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitMessageOnLines(t *testing.T) {
+	got := splitMessage("first line\nsecond line\nthird line\n", 26) // 3 bytes are kept free to close a code block
+	want := []string{"first line\nsecond line\n", "third line\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitMessageReopensCodeBlock(t *testing.T) {
+	msg := "> Winner(s)\n```ini\n1: Mortimus\n2: Bramil\n3: Ravnor\n```"
+	got := splitMessage(msg, 40)
+	want := []string{"> Winner(s)\n```ini\n1: Mortimus\n```", "```ini\n2: Bramil\n3: Ravnor\n```"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitMessage() = %q, want %q", got, want)
+	}
+	for _, chunk := range got {
+		if len(chunk) > 40 {
+			t.Errorf("len(%q) = %d, want at most 40", chunk, len(chunk))
+		}
+		if strings.Count(chunk, codeFence)%2 != 0 {
+			t.Errorf("chunk %q leaves a code block open", chunk)
+		}
+	}
+}
+
+func TestSplitMessageLongLine(t *testing.T) {
+	msg := strings.Repeat("é", 30) // 60 bytes, no line breaks
+	got := splitMessage(msg, 25)
+	if strings.Join(got, "") != msg {
+		t.Errorf("splitMessage() = %q, want the message back when joined", got)
+	}
+	for _, chunk := range got {
+		if len(chunk) > 25 || !strings.HasPrefix(chunk, "é") {
+			t.Errorf("chunk %q is too long or cut inside a character", chunk)
+		}
+	}
+}
+
+func TestOpenFence(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"no code", ""},
+		{"```ini\n[Mortimus rolled a 523]\n```", ""},
+		{"```ini\n[Mortimus rolled", "```ini\n"},
+		{"> Looted\n```Spell: Form of the Great Bear\nMAGIC", "```"},
+		{"```\nVex Thal", "```\n"},
+	}
+	for _, tt := range tests {
+		got := openFence(tt.text)
+		if got != tt.want {
+			t.Errorf("openFence(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func restError(status int, retryAfter string) error {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return &discordgo.RESTError{Response: resp}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		attempt   int
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{"rate limited", restError(http.StatusTooManyRequests, "2.5"), 1, 2500 * time.Millisecond, true},
+		{"rate limited no header", restError(http.StatusTooManyRequests, ""), 2, 2 * time.Second, true},
+		{"server error", restError(http.StatusBadGateway, ""), 3, 4 * time.Second, true},
+		{"missing permissions", restError(http.StatusForbidden, ""), 1, 0, false},
+		{"webhook rate limited", &webhookError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}, 1, time.Second, true},
+		{"network", errors.New("connection reset by peer"), 10, outboxMaxBackoff, true},
+	}
+	for _, tt := range tests {
+		delay, retry := retryDelay(tt.err, tt.attempt)
+		if delay != tt.wantDelay || retry != tt.wantRetry {
+			t.Errorf("%s: retryDelay() = %s, %t, want %s, %t", tt.name, delay, retry, tt.wantDelay, tt.wantRetry)
+		}
+	}
+}
+
+func TestOutboxRetriesInOrder(t *testing.T) {
+	o := newOutbox(10)
+	o.sleep = func(time.Duration) {}
+	var mu sync.Mutex
+	var sent []string
+	failures := 2
+	done := make(chan bool)
+	send := func(content string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if content == "first" && failures > 0 {
+			failures--
+			return restError(http.StatusTooManyRequests, "0.01")
+		}
+		sent = append(sent, content)
+		if content == "third" {
+			done <- true
+		}
+		return nil
+	}
+	for _, content := range []string{"first", "second", "third"} {
+		if err := o.Enqueue("loot", content, send); err != nil {
+			t.Fatalf("o.Enqueue(%q) error = %s", content, err)
+		}
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("outbox did not send the queued messages")
+	}
+	mu.Lock()
+	got := sent
+	mu.Unlock()
+	want := []string{"first", "second", "third"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sent = %v, want %v", got, want)
+	}
+	metrics := o.Metrics()
+	if metrics.Queued != 3 || metrics.Retried != 2 {
+		t.Errorf("o.Metrics() = %s, want 3 queued and 2 retried", metrics)
+	}
+}
+
+func TestOutboxDropsWhenFull(t *testing.T) {
+	o := newOutbox(1)
+	block := make(chan bool)
+	defer close(block)
+	send := func(content string) error {
+		<-block
+		return nil
+	}
+	var dropped int
+	for i := 0; i < 5; i++ {
+		if err := o.Enqueue("loot", "boss down", send); err == errOutboxFull {
+			dropped++
+		}
+	}
+	// one message is being sent and one waits, the rest are dropped
+	if dropped < 3 {
+		t.Errorf("dropped = %d, want at least 3", dropped)
+	}
+	got := o.Metrics().Dropped
+	if got != dropped {
+		t.Errorf("o.Metrics().Dropped = %d, want %d", got, dropped)
+	}
+}
+
+func TestOutboxSendWaitsInOrder(t *testing.T) {
+	o := newOutbox(10)
+	var sent []string
+	send := func(content string) error {
+		sent = append(sent, content)
+		return nil
+	}
+	o.Enqueue("loot", "first", send)
+	err := o.Send("loot", "second", send)
+	if err != nil {
+		t.Fatalf("o.Send() error = %s", err)
+	}
+	want := []string{"first", "second"}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent = %v, want %v", sent, want)
+	}
+}
+
+func TestOutboxSendReturnsError(t *testing.T) {
+	o := newOutbox(10)
+	err := o.Send("loot", "boss down", func(string) error { return restError(http.StatusForbidden, "") })
+	if err == nil {
+		t.Errorf("o.Send() error = nil, want the missing permissions error")
+	}
+}
+
+func TestOutboxFlush(t *testing.T) {
+	o := newOutbox(10)
+	var mu sync.Mutex
+	var sent int
+	for i := 0; i < 3; i++ {
+		o.Call("dumps", "raid dump", func() error {
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			sent++
+			mu.Unlock()
+			return nil
+		})
+	}
+	if !o.Flush(5 * time.Second) {
+		t.Fatalf("o.Flush() = false, want the queue emptied")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if sent != 3 {
+		t.Errorf("sent = %d after flushing, want 3", sent)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -8,7 +9,16 @@ import (
 	everquest "github.com/Mortimus/goEverquest"
 )
 
-type ParsePlugin Plugin
+const (
+	defaultParseChannel    = "von_parses"
+	defaultParseIdentifier = "s, "
+)
+
+type ParsePlugin struct {
+	Plugin
+	Channel    string // everquest channel parses are pasted to
+	Identifier string // text every parse contains
+}
 
 func init() {
 	plug := new(ParsePlugin)
@@ -16,12 +26,36 @@ func init() {
 	plug.Author = "Mortimus"
 	plug.Version = "1.0.0"
 	plug.Output = PARSEOUT
-	Handlers = append(Handlers, plug)
+	plug.Channel = configuration.Everquest.ParseChannel
+	plug.Identifier = configuration.Everquest.ParseIdentifier
+	registerPlugin("parse", plug)
+}
+
+// Configure for ParsePlugin takes the channel and identifier settings
+func (p *ParsePlugin) Configure(settings map[string]string) error {
+	for key, value := range settings {
+		switch key {
+		case "channel":
+			p.Channel = value
+		case "identifier":
+			p.Identifier = value
+		default:
+			return errors.New("unknown setting: " + key)
+		}
+	}
+	return nil
 }
 
 // Handle for ParsePlugin sends a message if a parse was pasted to the parse channel
 func (p *ParsePlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if strings.Contains(strings.ToLower(msg.Channel), "von_parses") && strings.Contains(msg.Msg, "s, ") {
+	channel, identifier := p.Channel, p.Identifier
+	if channel == "" {
+		channel = defaultParseChannel
+	}
+	if identifier == "" {
+		identifier = defaultParseIdentifier
+	}
+	if strings.Contains(strings.ToLower(msg.Channel), strings.ToLower(channel)) && strings.Contains(msg.Msg, identifier) {
 		if msg.Source == "You" {
 			msg.Source = getPlayerName(configuration.Everquest.LogPath)
 		}
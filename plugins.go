@@ -2,6 +2,8 @@ package main
 
 import (
 	"io"
+	"os"
+	"time"
 
 	everquest "github.com/Mortimus/goEverquest"
 )
@@ -9,11 +11,20 @@ import (
 var Handlers []LogHandler
 
 type LogHandler interface {
-	Handle(msg *everquest.EqLog, out io.Writer)
 	Info(out io.Writer)
 	OutputChannel() int
 }
 
+// LineHandler is implemented by plugins that read raw log lines instead of, or as well as, events from the bus
+type LineHandler interface {
+	Handle(msg *everquest.EqLog, out io.Writer)
+}
+
+// TickHandler is implemented by plugins that need to act on time passing, not just on new log lines
+type TickHandler interface {
+	Tick(now time.Time, out io.Writer)
+}
+
 type Plugin struct {
 	Name    string
 	Version string
@@ -36,39 +47,41 @@ type DiscordWriter struct {
 	Channel string
 }
 
+// Write for DiscordWriter queues the message on the outbox so parsing never waits on discord
 func (dw *DiscordWriter) Write(p []byte) (n int, err error) {
-	const maxMessageLength = 1000
-	n = len(p)
-	for len(p) > 0 {
-		// n = len(p)
-		if len(p) > maxMessageLength {
-			discord.ChannelMessageSend(dw.Channel, string(p[:maxMessageLength]))
-			p = p[maxMessageLength:]
-		} else {
-			_, err = discord.ChannelMessageSend(dw.Channel, string(p[:]))
-			break
+	for _, chunk := range splitMessage(string(p), maxMessageLength) {
+		err = outbox.Enqueue(dw.Channel, chunk, dw.send)
+		if err != nil {
+			return len(p), err
 		}
 	}
+	return len(p), nil
+}
 
-	// _, err = discord.ChannelMessageSend(dw.Channel, string(p[:]))
-
-	return n, err
+func (dw *DiscordWriter) send(content string) error {
+	_, err := discord.ChannelMessageSend(dw.Channel, content)
+	return err
 }
 
-var BidWriter DiscordWriter
-var InvestigateWriter DiscordWriter
-var RaidWriter DiscordWriter
-var SpellWriter DiscordWriter
-var FlagWriter DiscordWriter
-var ParseWriter DiscordWriter
+// SendFile for DiscordWriter queues a file upload to the channel behind its messages
+func (dw *DiscordWriter) SendFile(name string, r io.Reader) error {
+	return discordFile(dw.Channel, name, r)
+}
 
 func init() {
-	BidWriter.Channel = configuration.Discord.LootChannelID
-	InvestigateWriter.Channel = configuration.Discord.InvestigationChannelID
-	RaidWriter.Channel = configuration.Discord.RaidDumpChannelID
-	SpellWriter.Channel = configuration.Discord.SpellDumpChannelID
-	FlagWriter.Channel = configuration.Discord.FlagChannelID
-	ParseWriter.Channel = configuration.Discord.ParseChannelID
+	outbox = newOutbox(configuration.Discord.OutboxSize)
+	routes = buildRoutes(configuration.Routes)
+}
+
+// getOutput returns the writer for the route a handler is sent to
+func getOutput(handler LogHandler) io.Writer {
+	if route, ok := routes[routeName(handler)]; ok {
+		if identity, ok := pluginIdentities[handler]; ok {
+			return route.as(identity)
+		}
+		return route
+	}
+	return os.Stdout
 }
 
 func printPlugins(out io.Writer) {
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Charge is the DKP a character pays for an item, a negative cost is a credit
+type Charge struct {
+	Name string
+	Cost int
+	Type string // ledger type column, Spent when empty
+}
+
+// PricingStrategy decides what a winner pays, called once bids are sorted and winners applied
+type PricingStrategy interface {
+	Price(b *OpenBid, winner *Bidder) int
+}
+
+// CreditingStrategy is implemented by pricing strategies that give the spent DKP back to someone
+type CreditingStrategy interface {
+	Credits(b *OpenBid, spent []Charge) []Charge
+}
+
+// SecondPrice charges the next highest bid plus an increment, dropping to the minimum across tiers
+type SecondPrice struct{}
+
+func (p SecondPrice) Price(b *OpenBid, winner *Bidder) int {
+	return b.FindWinningBid()
+}
+
+// FirstPrice charges each winner what they bid
+type FirstPrice struct{}
+
+func (p FirstPrice) Price(b *OpenBid, winner *Bidder) int {
+	return winner.Bid
+}
+
+// FixedPrice charges every slot the same configured price
+type FixedPrice struct {
+	Cost int
+}
+
+func (p FixedPrice) Price(b *OpenBid, winner *Bidder) int {
+	return p.Cost
+}
+
+// ZeroSum charges the second price and splits what was spent evenly across the mains in the raid
+type ZeroSum struct {
+	SecondPrice
+}
+
+func (p ZeroSum) Credits(b *OpenBid, spent []Charge) []Charge {
+	var total int
+	for _, charge := range spent {
+		total += charge.Cost
+	}
+	mains := raidMains(currentRaidMembers())
+	if total <= 0 || len(mains) == 0 {
+		if total > 0 {
+			Warn.Printf("No roster members in the raid dump, %d DKP spent on %s was not split", total, b.Item.Name)
+		}
+		return nil
+	}
+	share := total / len(mains)
+	remainder := total % len(mains) // the first mains alphabetically get 1 more so nothing is lost
+	var credits []Charge
+	for i, main := range mains {
+		credit := share
+		if i < remainder {
+			credit++
+		}
+		if credit > 0 {
+			credits = append(credits, Charge{Name: main, Cost: -credit, Type: "Zero Sum"})
+		}
+	}
+	return credits
+}
+
+// raidMains maps raid members to their mains so a main with an alt in the raid is only counted once,
+// members missing from the roster are left out as their credit could not be written to the ledger
+func raidMains(members []string) []string {
+	seen := make(map[string]bool)
+	var mains []string
+	for _, member := range members {
+		holder, ok := Roster[member]
+		if !ok {
+			continue
+		}
+		main := getMain(&holder.GuildMember)
+		if seen[main] {
+			continue
+		}
+		seen[main] = true
+		if _, ok := Roster[main]; !ok {
+			main = member // credited through the alt, the ledger row still goes to the main
+		}
+		mains = append(mains, main)
+	}
+	sort.Strings(mains)
+	return mains
+}
+
+// getPricing builds the pricing strategy selected in the configuration
+func getPricing(mode string) (PricingStrategy, error) {
+	switch strings.ToLower(mode) {
+	case "", "second-price":
+		return SecondPrice{}, nil
+	case "first-price":
+		return FirstPrice{}, nil
+	case "fixed-price":
+		cost := configuration.Bids.FixedPrice
+		if cost <= 0 {
+			cost = configuration.Bids.MinimumBid
+		}
+		return FixedPrice{Cost: cost}, nil
+	case "zero-sum":
+		return ZeroSum{}, nil
+	}
+	return nil, errors.New("unknown pricing mode: " + mode)
+}
+
+// PriceWinners sets what each winner pays and returns the charges, including any credits from the strategy
+func (b *OpenBid) PriceWinners(pricing PricingStrategy) []Charge {
+	var charges []Charge
+	for _, bidder := range b.Bidders {
+		if !bidder.WonOrTied {
+			continue
+		}
+		price := pricing.Price(b, bidder)
+		if price < 0 {
+			price = 0
+		}
+		bidder.Price = price
+		charges = append(charges, Charge{Name: bidder.Player.Name, Cost: price})
+	}
+	if crediting, ok := pricing.(CreditingStrategy); ok {
+		charges = append(charges, crediting.Credits(b, charges)...)
+	}
+	return charges
+}
+
+// currentRaidMembers lists the characters in the latest raid dump
+func currentRaidMembers() []string {
+	var members []string
+	for _, handler := range Handlers {
+		if plug, ok := handler.(*RaidPlugin); ok {
+			for _, member := range plug.LastRaid.Members {
+				members = append(members, member.Player)
+			}
+		}
+	}
+	return members
+}
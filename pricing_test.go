@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func pricingTestBid(quantity int, bids map[string]int) *OpenBid {
+	bid := &OpenBid{Quantity: quantity}
+	for name, amount := range bids {
+		Roster[name] = &DKPHolder{GuildMember: everquest.GuildMember{Name: name}, DKP: 1000, DKPRank: MAIN}
+		bid.Bidders = append(bid.Bidders, &Bidder{Player: Roster[name], AttemptedBid: amount, Bid: amount})
+	}
+	bid.SortBids()
+	bid.CheckTiesAndApplyWinners()
+	return bid
+}
+
+func TestPricingStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		pricing  PricingStrategy
+		quantity int
+		bids     map[string]int
+		want     map[string]int
+	}{
+		{"second-price pays next bid plus increment", SecondPrice{}, 1, map[string]int{"Pricea": 100, "Priceb": 60}, map[string]int{"Pricea": 65}},
+		{"second-price pays the tie", SecondPrice{}, 1, map[string]int{"Pricea": 100, "Priceb": 100}, map[string]int{"Pricea": 100, "Priceb": 100}},
+		{"first-price pays own bid", FirstPrice{}, 1, map[string]int{"Pricea": 100, "Priceb": 60}, map[string]int{"Pricea": 100}},
+		{"first-price pays own bid per slot", FirstPrice{}, 2, map[string]int{"Pricea": 100, "Priceb": 60, "Pricec": 20}, map[string]int{"Pricea": 100, "Priceb": 60}},
+		{"fixed-price per slot", FixedPrice{Cost: 50}, 2, map[string]int{"Pricea": 100, "Priceb": 60, "Pricec": 20}, map[string]int{"Pricea": 50, "Priceb": 50}},
+		{"zero-sum pays second price", ZeroSum{}, 1, map[string]int{"Pricea": 100, "Priceb": 60}, map[string]int{"Pricea": 65}},
+	}
+	for _, tt := range tests {
+		bid := pricingTestBid(tt.quantity, tt.bids)
+		got := make(map[string]int)
+		for _, charge := range bid.PriceWinners(tt.pricing) {
+			if charge.Type == "" {
+				got[charge.Name] = charge.Cost
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: PriceWinners() = %v; want %v", tt.name, got, tt.want)
+			continue
+		}
+		for name, want := range tt.want {
+			if got[name] != want {
+				t.Errorf("%s: %s paid %d; want %d", tt.name, name, got[name], want)
+			}
+		}
+	}
+}
+
+// testRaidPlugin finds the registered raid plugin, failing the test when it is not loaded
+func testRaidPlugin(t *testing.T) *RaidPlugin {
+	t.Helper()
+	for _, handler := range Handlers {
+		if plug, ok := handler.(*RaidPlugin); ok {
+			return plug
+		}
+	}
+	t.Fatal("raid plugin is not registered")
+	return nil
+}
+
+func TestZeroSumCredits(t *testing.T) {
+	raid := testRaidPlugin(t)
+	oldRaid := raid.LastRaid
+	raid.LastRaid = everquest.Raid{Members: []everquest.RaidMember{{Player: "Pricea"}, {Player: "Priceb"}}}
+	defer func() { raid.LastRaid = oldRaid }()
+	bid := pricingTestBid(1, map[string]int{"Pricea": 100, "Priceb": 60})
+	var credited int
+	for _, charge := range bid.PriceWinners(ZeroSum{}) {
+		if charge.Type == "Zero Sum" {
+			credited -= charge.Cost
+		}
+	}
+	want := 65 // split two ways, the odd point goes to Pricea
+	if credited != want {
+		t.Errorf("ZeroSum credited %d; want %d", credited, want)
+	}
+}
+
+func TestZeroSumCreditsMainsOnce(t *testing.T) {
+	raid := testRaidPlugin(t)
+	oldRaid := raid.LastRaid
+	raid.LastRaid = everquest.Raid{Members: []everquest.RaidMember{{Player: "Pricea"}, {Player: "Priceb"}, {Player: "Pricealt"}}}
+	defer func() { raid.LastRaid = oldRaid }()
+	bid := pricingTestBid(1, map[string]int{"Pricea": 100, "Priceb": 60})
+	Roster["Pricealt"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Pricealt", Alt: true, PublicNote: "Priceb's Alt"}, DKPRank: ALT}
+	credits := make(map[string]int)
+	for _, charge := range bid.PriceWinners(ZeroSum{}) {
+		if charge.Type == "Zero Sum" {
+			credits[charge.Name] -= charge.Cost
+		}
+	}
+	want := map[string]int{"Pricea": 33, "Priceb": 32}
+	if !reflect.DeepEqual(credits, want) {
+		t.Errorf("ZeroSum credits = %v; want %v", credits, want)
+	}
+}
+
+func TestZeroSumCreditsRosterOnly(t *testing.T) {
+	raid := testRaidPlugin(t)
+	oldRaid := raid.LastRaid
+	raid.LastRaid = everquest.Raid{Members: []everquest.RaidMember{{Player: "Pricea"}, {Player: "Priceb"}, {Player: "Pricepug"}}}
+	defer func() { raid.LastRaid = oldRaid }()
+	bid := pricingTestBid(1, map[string]int{"Pricea": 100, "Priceb": 60})
+	delete(Roster, "Pricepug")
+	credits := make(map[string]int)
+	for _, charge := range bid.PriceWinners(ZeroSum{}) {
+		if charge.Type == "Zero Sum" {
+			credits[charge.Name] -= charge.Cost
+		}
+	}
+	want := map[string]int{"Pricea": 33, "Priceb": 32}
+	if !reflect.DeepEqual(credits, want) {
+		t.Errorf("ZeroSum credits = %v; want %v", credits, want)
+	}
+}
+
+func TestGetPricingUnknown(t *testing.T) {
+	_, err := getPricing("vickrey-clarke")
+	if err == nil {
+		t.Errorf("getPricing(\"vickrey-clarke\") returned no error")
+	}
+}
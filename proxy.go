@@ -0,0 +1,31 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// proxyMatch finds "for Mortimus 50" in a tell, an officer bidding for a linkdead or absent member
+var proxyMatch = regexp.MustCompile(`(?i)(?:^|\s)for\s+([a-z]+)\s+\d`)
+
+// proxyBeneficiary returns who a tell bids for, empty when the sender bids for themselves
+func proxyBeneficiary(bidString string) string {
+	result := proxyMatch.FindStringSubmatch(bidString)
+	if len(result) < 2 {
+		return ""
+	}
+	return strings.Title(strings.ToLower(result[1]))
+}
+
+// canProxyBid is true for characters, or members of guild ranks, listed in ProxyBidders
+func canProxyBid(player string) bool {
+	for _, allowed := range configuration.Bids.ProxyBidders {
+		if strings.EqualFold(allowed, player) {
+			return true
+		}
+		if member, ok := Roster[player]; ok && strings.EqualFold(allowed, member.Rank) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestProxyBeneficiary(t *testing.T) {
+	tests := []struct {
+		bid  string
+		want string
+	}{
+		{" for Mortimus 50", "Mortimus"},
+		{"for mortimus 50", "Mortimus"},
+		{" 50", ""},
+		{" 50 for now", ""},
+	}
+	for _, tt := range tests {
+		if got := proxyBeneficiary(tt.bid); got != tt.want {
+			t.Errorf("proxyBeneficiary(%q) = %q, want %q", tt.bid, got, tt.want)
+		}
+	}
+}
+
+func TestProxyBid(t *testing.T) {
+	configuration.Bids.ProxyBidders = []string{"Proxyofficer"}
+	defer func() { configuration.Bids.ProxyBidders = nil }()
+	Roster["Proxyofficer"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Proxyofficer"}, DKP: 100, DKPRank: MAIN}
+	Roster["Proxymember"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Proxymember"}, DKP: 100, DKPRank: MAIN}
+	Roster["Proxyrandom"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Proxyrandom"}, DKP: 100, DKPRank: MAIN}
+	plug := new(BidPlugin)
+	plug.BidNumber, _ = regexp.Compile(`\d+`)
+	plug.Bids = make(map[int]*OpenBid)
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	plug.Bids[id] = &OpenBid{Item: item, Quantity: 1}
+
+	plug.HandleTell(&everquest.EqLog{Channel: "tell", Source: "Proxyrandom", Msg: "Cloth Cap for Proxymember 50", T: time.Now()})
+	if pos := plug.Bids[id].FindBid("Proxymember"); pos >= 0 {
+		t.Errorf("HandleTell() took a proxy bid from a member without proxy rights")
+	}
+	plug.HandleTell(&everquest.EqLog{Channel: "tell", Source: "Proxyofficer", Msg: "Cloth Cap for Proxymember 50", T: time.Now()})
+	pos := plug.Bids[id].FindBid("Proxymember")
+	if pos < 0 {
+		t.Fatalf("HandleTell() did not bid for Proxymember")
+	}
+	if got := plug.Bids[id].Bidders[pos].Proxy; got != "Proxyofficer" {
+		t.Errorf("Bidder.Proxy = %q, want %q", got, "Proxyofficer")
+	}
+	if got := plug.Bids[id].FindBid("Proxyofficer"); got >= 0 {
+		t.Errorf("HandleTell() also bid for the officer")
+	}
+}
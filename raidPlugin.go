@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -20,7 +19,6 @@ type RaidPlugin struct {
 	Bosses    int
 	NeedsDump bool
 	LastBoss  string
-	SlayMatch *regexp.Regexp
 	Start     time.Time
 	NextDump  time.Time
 	Started   bool
@@ -33,114 +31,135 @@ func init() {
 	plug.Author = "Mortimus"
 	plug.Version = "1.0.0"
 	plug.Output = RAIDOUT
-	Handlers = append(Handlers, plug)
+	registerPlugin("raid", plug)
 	plug.NeedsDump = true
 	plug.LastBoss = "Unknown"
 	plug.LastRaid = everquest.Raid{}
+}
 
-	plug.SlayMatch, _ = regexp.Compile(configuration.Everquest.RegexSlay)
+// Tick asks for the hourly dump when no log lines are coming in
+func (p *RaidPlugin) Tick(now time.Time, out io.Writer) {
+	p.checkDump(out)
 }
 
-// Handle for ParsePlugin sends a message if a parse was pasted to the parse channel
-func (p *RaidPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
+func (p *RaidPlugin) checkDump(out io.Writer) {
 	if p.Started && !p.NeedsDump && getTime().Round(5*time.Minute) == p.NextDump.Round(5*time.Minute) {
 		fmt.Fprintf(out, "Time for another hourly raid dump!\n")
 		p.NeedsDump = true
 	}
-	if p.Started && msg.Channel == "system" && strings.Contains(msg.Msg, "has been slain by ") { // A spectre has been slain by Mortimus!
-		// fmt.Fprintf(out, "TEST: %s\n", msg.Msg)
-		Boss := p.SlayMatch.FindStringSubmatch(msg.Msg)[1]
-		Slayer := p.SlayMatch.FindStringSubmatch(msg.Msg)[2]
-		if !strings.EqualFold(Boss, p.LastBoss) {
-			lowerBoss := strings.ToLower(Boss)
-			if _, ok := bosses[lowerBoss]; ok {
-				if bosses[lowerBoss].IsFTK {
-					fmt.Fprintf(out, "%s was slain by %s awarding the raid %d+%d=%d DKP due to FTK\n", Boss, Slayer, bosses[lowerBoss].DKP, bosses[lowerBoss].FTK, bosses[lowerBoss].DKP+bosses[lowerBoss].FTK)
-				} else {
-					fmt.Fprintf(out, "%s was slain by %s awarding the raid %d DKP\n", Boss, Slayer, bosses[lowerBoss].DKP)
-				}
+}
 
-				p.LastBoss = Boss
-			}
+func (p *RaidPlugin) Subscriptions() []EventType {
+	return []EventType{EventSlain, EventDumpWritten}
+}
+
+func (p *RaidPlugin) HandleEvent(event Event, out io.Writer) {
+	switch e := event.(type) {
+	case SlainEvent:
+		if p.Started {
+			p.bossSlain(e.Target, e.Slayer, out)
+		}
+	case DumpWrittenEvent:
+		if strings.Contains(e.File, "RaidRoster") {
+			p.raidDumped(e.File, e.Log.T, out)
 		}
 	}
-	if msg.Channel == "system" && strings.Contains(msg.Msg, "Outputfile") && strings.Contains(msg.Msg, "RaidRoster") {
-		// Upload the Raid Dump
-		outputName := msg.Msg[21:] // Filename Outputfile sent data to
+}
 
-		stamp := msg.T.Format("20060102")
-		dkpExportName := "DKP_" + TimeStamp() + ".csv"
-		exportDKP("backup/" + dkpExportName)
-		dkpfile, err := os.Open("backup/" + dkpExportName)
-		if err != nil {
-			fmt.Fprintf(out, "Error finding DKP Dump: %s\n", outputName)
-		} else {
-			if configuration.Discord.UseDiscord {
-				discord.ChannelFileSend(configuration.Discord.DKPArchiveChannelID, dkpExportName, dkpfile)
+func (p *RaidPlugin) bossSlain(boss string, slayer string, out io.Writer) {
+	if !strings.EqualFold(boss, p.LastBoss) {
+		lowerBoss := strings.ToLower(boss)
+		if _, ok := bosses[lowerBoss]; ok {
+			if bosses[lowerBoss].IsFTK {
+				fmt.Fprintf(out, "%s was slain by %s awarding the raid %d+%d=%d DKP due to FTK\n", boss, slayer, bosses[lowerBoss].DKP, bosses[lowerBoss].FTK, bosses[lowerBoss].DKP+bosses[lowerBoss].FTK)
+			} else {
+				fmt.Fprintf(out, "%s was slain by %s awarding the raid %d DKP\n", boss, slayer, bosses[lowerBoss].DKP)
 			}
+
+			p.LastBoss = boss
 		}
-		var fileName string
-		if !p.NeedsDump { // Boss Kill
-			formattedBoss := strings.Replace(p.LastBoss, " ", "_", -1)  // Remove Spaces
-			formattedBoss = strings.Replace(formattedBoss, "`", "", -1) // Remove `
-			formattedBoss = strings.Replace(formattedBoss, "'", "", -1) // Remove '
-			fileName = fmt.Sprintf("%s_%s_%d.txt", stamp, formattedBoss, p.Bosses)
-			p.LastBoss = "Unknown"
-			p.Bosses++
-		}
-		if p.NeedsDump && p.Hours == 0 {
-			fileName = stamp + "_raid_start.txt"
-			p.NeedsDump = false
-			p.Hours++
-			p.Start = getTime().Round(1 * time.Hour)
-			p.NextDump = msg.T.Add(1 * time.Hour)
-			p.Started = true
-			err := p.LastRaid.LoadFromPath(configuration.Everquest.BaseFolder+"/"+outputName, Err)
+	}
+}
+
+func (p *RaidPlugin) raidDumped(outputName string, dumped time.Time, out io.Writer) {
+	// Upload the Raid Dump
+	stamp := dumped.Format("20060102")
+	dkpExportName := "DKP_" + TimeStamp() + ".csv"
+	exportDKP("backup/" + dkpExportName)
+	dkpfile, err := os.Open("backup/" + dkpExportName)
+	if err != nil {
+		fmt.Fprintf(out, "Error finding DKP Dump: %s\n", outputName)
+	} else {
+		if configuration.Discord.UseDiscord {
+			err = discordFile(configuration.Discord.DKPArchiveChannelID, dkpExportName, dkpfile)
 			if err != nil {
-				Err.Printf("Error loading new raid: %s\n", err)
+				Err.Printf("Error sending %s: %s", dkpExportName, err.Error())
 			}
 		}
-		if p.NeedsDump && p.Hours > 0 {
-			fileName = fmt.Sprintf("%s_hour_%d.txt", stamp, p.Hours)
-			p.NeedsDump = false
-			p.Hours++
-			p.NextDump = msg.T.Add(1 * time.Hour)
+		dkpfile.Close()
+	}
+	var fileName string
+	if !p.NeedsDump { // Boss Kill
+		formattedBoss := strings.Replace(p.LastBoss, " ", "_", -1)  // Remove Spaces
+		formattedBoss = strings.Replace(formattedBoss, "`", "", -1) // Remove `
+		formattedBoss = strings.Replace(formattedBoss, "'", "", -1) // Remove '
+		fileName = fmt.Sprintf("%s_%s_%d.txt", stamp, formattedBoss, p.Bosses)
+		p.LastBoss = "Unknown"
+		p.Bosses++
+	}
+	if p.NeedsDump && p.Hours == 0 {
+		fileName = stamp + "_raid_start.txt"
+		p.NeedsDump = false
+		p.Hours++
+		p.Start = getTime().Round(1 * time.Hour)
+		p.NextDump = dumped.Add(1 * time.Hour)
+		p.Started = true
+		err := p.LastRaid.LoadFromPath(configuration.Everquest.BaseFolder+"/"+outputName, Err)
+		if err != nil {
+			Err.Printf("Error loading new raid: %s\n", err)
 		}
-		if p.Output == RAIDOUT { // Send to discord as an upload
-			file, err := os.Open(configuration.Everquest.BaseFolder + "/" + outputName)
-			if err != nil {
-				fmt.Fprintf(out, "Error finding Raid Dump: %s\n", outputName)
-			} else {
-				discord.ChannelFileSend(configuration.Discord.RaidDumpChannelID, fileName, file)
-			}
-			// uploadRaidDump(outputName)
+	}
+	if p.NeedsDump && p.Hours > 0 {
+		fileName = fmt.Sprintf("%s_hour_%d.txt", stamp, p.Hours)
+		p.NeedsDump = false
+		p.Hours++
+		p.NextDump = dumped.Add(1 * time.Hour)
+	}
+	if p.Output == RAIDOUT { // Send to discord as an upload
+		file, err := os.Open(configuration.Everquest.BaseFolder + "/" + outputName)
+		if err != nil {
+			fmt.Fprintf(out, "Error finding Raid Dump: %s\n", outputName)
 		} else {
-			fmt.Fprintf(out, "Uploading Raid Dump: %s\n", fileName)
+			sendFile("dumps", fileName, file)
+			file.Close()
 		}
-		if p.Started {
-			// Diff the Raid Dump
-			newRaid := everquest.Raid{}
-			err := newRaid.LoadFromPath(configuration.Everquest.BaseFolder+"/"+outputName, Err)
-			if err != nil {
-				Err.Printf("Error loading new raid: %s\n", err)
-			}
-			// newMembers := everquest.NewRaidMembers(*p.LastRaid, newRaid)
-			// missMembers := everquest.MissingRaidMembers(*p.LastRaid, newRaid)
-			newMembers, missMembers := p.DiffRaid(newRaid)
-			p.LastRaid = newRaid
-			// diffString := fmt.Sprintf("")
-			var diffString string
-			for _, member := range newMembers {
-				diffString += fmt.Sprintf("```diff\n+ %s\n```", member.Player)
-				// fmt.Fprintf(out, "```diff\n+ %s\n```", member.Player)
-			}
-			for _, member := range missMembers {
-				diffString += fmt.Sprintf("```diff\n- %s\n```", member.Player)
-				// fmt.Fprintf(out, "```diff\n- %s\n```", member.Player)
-			}
-			// diffString += fmt.Sprintf("\n```")
-			fmt.Fprintf(out, "%s", diffString)
+		// uploadRaidDump(outputName)
+	} else {
+		fmt.Fprintf(out, "Uploading Raid Dump: %s\n", fileName)
+	}
+	if p.Started {
+		// Diff the Raid Dump
+		newRaid := everquest.Raid{}
+		err := newRaid.LoadFromPath(configuration.Everquest.BaseFolder+"/"+outputName, Err)
+		if err != nil {
+			Err.Printf("Error loading new raid: %s\n", err)
+		}
+		// newMembers := everquest.NewRaidMembers(*p.LastRaid, newRaid)
+		// missMembers := everquest.MissingRaidMembers(*p.LastRaid, newRaid)
+		newMembers, missMembers := p.DiffRaid(newRaid)
+		p.LastRaid = newRaid
+		// diffString := fmt.Sprintf("")
+		var diffString string
+		for _, member := range newMembers {
+			diffString += fmt.Sprintf("```diff\n+ %s\n```", member.Player)
+			// fmt.Fprintf(out, "```diff\n+ %s\n```", member.Player)
+		}
+		for _, member := range missMembers {
+			diffString += fmt.Sprintf("```diff\n- %s\n```", member.Player)
+			// fmt.Fprintf(out, "```diff\n- %s\n```", member.Player)
 		}
+		// diffString += fmt.Sprintf("\n```")
+		fmt.Fprintf(out, "%s", diffString)
 	}
 }
 
@@ -191,26 +210,24 @@ var bosses map[string]*BossDKP
 
 func seedBosses() {
 	bosses = make(map[string]*BossDKP)
-	spreadsheetID := configuration.Sheets.RawSheetURL
-	readRange := configuration.Sheets.BossesSheetName
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
+	rows, err := dkpStore.LoadBosses()
 	if err != nil {
 		Err.Printf("Unable to retrieve data from sheet: %v", err)
 		DiscordF(configuration.Discord.InvestigationChannelID, "Unable to read data from the Bosses sheet, cannot determine kills! - %s\n", err)
-		// return errors.New("Unable to retrieve data from sheet: " + err.Error())
+		return
 	}
 
-	if len(resp.Values) == 0 {
-		Err.Printf("Cannot read bosses sheet: %v", resp)
+	if len(rows) == 0 {
+		Err.Printf("Cannot read bosses sheet: no rows")
 	} else {
-		for i, row := range resp.Values {
+		for i, row := range rows {
 			if i == 1 {
 				continue // skip the header
 			}
 			if len(row) < configuration.Sheets.BossSheetFTKCol {
 				continue // sheet is not formatted correctly
 			}
-			boss := fmt.Sprintf("%s", row[configuration.Sheets.BossSheetBossCol])
+			boss := cell(row, configuration.Sheets.BossSheetBossCol)
 			i := strings.Index(boss, ":")
 			if i > -1 {
 				boss = boss[i+1:]
@@ -221,15 +238,15 @@ func seedBosses() {
 				var newBoss BossDKP
 				newBoss.Boss = boss
 
-				zone := fmt.Sprintf("%s", row[configuration.Sheets.BossSheetZoneCol])
+				zone := cell(row, configuration.Sheets.BossSheetZoneCol)
 				zone = strings.TrimSpace(zone)
 				newBoss.Zone = zone
 
-				note := fmt.Sprintf("%s", row[configuration.Sheets.BossSheetNoteCol])
+				note := cell(row, configuration.Sheets.BossSheetNoteCol)
 				note = strings.TrimSpace(note)
 				newBoss.Note = note
 
-				dkpString := fmt.Sprintf("%s", row[configuration.Sheets.BossSheetDKPCol])
+				dkpString := cell(row, configuration.Sheets.BossSheetDKPCol)
 				dkpPoints, err := strconv.Atoi(dkpString)
 				if err != nil {
 					Err.Printf("Error converting dkp points to float at row %d: %s", i+1, err.Error())
@@ -238,7 +255,7 @@ func seedBosses() {
 				}
 				newBoss.DKP = dkpPoints
 
-				ftkString := fmt.Sprintf("%s", row[configuration.Sheets.BossSheetFTKCol])
+				ftkString := cell(row, configuration.Sheets.BossSheetFTKCol)
 				ftkPoints, err := strconv.Atoi(ftkString)
 				if err != nil {
 					Err.Printf("Error converting ftk points to float at row %d: %s", i+1, err.Error())
@@ -250,7 +267,7 @@ func seedBosses() {
 				isFTK := true
 				newBoss.IsFTK = isFTK
 				if len(row) > configuration.Sheets.BossSheetisFTKCol {
-					isFTKString := fmt.Sprintf("%s", row[configuration.Sheets.BossSheetisFTKCol])
+					isFTKString := cell(row, configuration.Sheets.BossSheetisFTKCol)
 					isFTKString = strings.TrimSpace(isFTKString)
 					if strings.EqualFold(isFTKString, "Yes") {
 						// fmt.Printf("isFTK: %s: %s\n", newBoss.Boss, isFTKString)
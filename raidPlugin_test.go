@@ -2,7 +2,6 @@ package main
 
 import (
 	"bytes"
-	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -20,11 +19,11 @@ func TestRaidStart(t *testing.T) {
 	ldplug.Output = TESTOUT // anything but raid dump channel
 	ldplug.NeedsDump = true
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Uploading Raid Dump: 20210417_raid_start.txt\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -40,14 +39,13 @@ func TestRaidBossKillGithub43(t *testing.T) {
 	ldplug.Output = TESTOUT // anything but raid dump channel
 	ldplug.NeedsDump = false
 	ldplug.NextDump = msg.T.Add(time.Hour * 5)
-	ldplug.SlayMatch, _ = regexp.Compile(`(.+) has been slain by (\w+)!`)
 	ldplug.Started = true
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Quintessence of Sand was slain by Mortimus awarding the raid 30 DKP\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -61,14 +59,13 @@ func TestRaidBossKill(t *testing.T) {
 	ldplug.Output = TESTOUT // anything but raid dump channel
 	ldplug.NeedsDump = false
 	ldplug.NextDump = msg.T.Add(time.Hour * 5)
-	ldplug.SlayMatch, _ = regexp.Compile(`(.+) has been slain by (\w+)!`)
 	ldplug.Started = true
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Kraksmaal Fir`Dethsin was slain by Mortimus awarding the raid 10 DKP\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -85,14 +82,13 @@ func TestRaidBossKillFTK(t *testing.T) {
 	ldplug.Output = TESTOUT // anything but raid dump channel
 	ldplug.NeedsDump = false
 	ldplug.NextDump = msg.T.Add(time.Hour * 5)
-	ldplug.SlayMatch, _ = regexp.Compile(`(.+) has been slain by (\w+)!`)
 	ldplug.Started = true
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Tunat`Muram Cuu Vauax was slain by Mortimus awarding the raid 10+10=20 DKP due to FTK\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 		// printBosses()
 	}
 }
@@ -110,11 +106,11 @@ func TestRaidBossUpload(t *testing.T) {
 	ldplug.LastBoss = "TestBoss"
 	ldplug.Bosses += 2
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Uploading Raid Dump: 20210417_TestBoss_2.txt\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -134,11 +130,11 @@ func TestRaidBossUploadDiff(t *testing.T) {
 	ldplug.Started = true
 	ldplug.Bosses += 2
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Uploading Raid Dump: 20210417_TestBoss_2.txt\n```diff\n+ Bids\n``````diff\n+ Amanar\n``````diff\n+ Guzz\n``````diff\n+ Daangerzone\n``````diff\n+ Beandip\n``````diff\n+ Canniblepper\n``````diff\n+ Blepper\n``````diff\n+ Kickfu\n``````diff\n+ Cronos\n``````diff\n+ Perc\n``````diff\n+ Mortimus\n``````diff\n+ Advenia\n``````diff\n+ Rabidtiger\n``````diff\n+ Kejek\n``````diff\n+ Kirynn\n``````diff\n+ Xarielx\n``````diff\n+ Tomdar\n``````diff\n+ Doctorbear\n``````diff\n+ Tators\n``````diff\n+ Rinon\n``````diff\n+ Wonders\n``````diff\n+ Helbinor\n``````diff\n+ Tyrannikal\n``````diff\n+ Ticklez\n``````diff\n+ Joule\n``````diff\n+ Valcis\n``````diff\n+ Thasumr\n``````diff\n+ Banis\n``````diff\n+ Nistalkin\n``````diff\n+ Boomerbear\n``````diff\n- Person\n``````diff\n- Crasis\n``````diff\n- Iovelost\n``````diff\n- Tigermancer\n``````diff\n- Galdo\n``````diff\n- Bunzz\n``````diff\n- Ayamnivay\n``````diff\n- Ravnor\n``````diff\n- Torsey\n``````diff\n- Cadenza\n``````diff\n- Coltaine\n``````diff\n- Mysfit\n``````diff\n- Dromi\n``````diff\n- Nosirrah\n``````diff\n- Talen\n``````diff\n- Utair\n``````diff\n- Whidon\n``````diff\n- Mollwin\n``````diff\n- Iilenye\n``````diff\n- Haldemir\n``````diff\n- Glooping\n``````diff\n- Wallen\n``````diff\n- Rokem\n``````diff\n- Fluffer\n``````diff\n- Milliardo\n``````diff\n- Ryze\n``````diff\n- Rost\n```"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q\nwant %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q\nwant %q", got, want)
 	}
 }
 
@@ -153,11 +149,11 @@ func TestRaidHourly(t *testing.T) {
 	ldplug.NeedsDump = true
 	ldplug.Hours++
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Uploading Raid Dump: 20210417_hour_1.txt\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -175,11 +171,11 @@ func TestRaidDumpReminder(t *testing.T) {
 	currentTime = msg.T
 	ldplug.Hours++
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := "Time for another hourly raid dump!\n"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -197,10 +193,10 @@ func TestRaidNoDumpReminder(t *testing.T) {
 	currentTime = msg.T.Add(time.Minute * 30)
 	ldplug.Hours++
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// RankRule maps guild ranks and public notes to a DKP tier, rules are checked in order and the first match wins
+type RankRule struct {
+	Ranks []string `comment:"Guild ranks this rule applies to, empty matches any rank"`
+	Note  string   `comment:"Regex the public note has to match, empty matches any note"`
+	Alt   string   `comment:"yes to only match alts, no to only match non alts, empty matches both"`
+	Tier  string   `comment:"DKP tier given: Main, Second Main, Recruit, Alt, Social or Inactive"`
+}
+
+type rankRule struct {
+	ranks []string
+	note  *regexp.Regexp
+	alt   string
+	tier  DKPRank
+}
+
+var rankPolicy []rankRule
+var altMatch *regexp.Regexp
+
+// defaultRankRules reproduces the original ranking when no rules are configured
+func defaultRankRules() []RankRule {
+	mainRanks := configuration.Everquest.GuildRaidingRanks
+	if len(mainRanks) == 0 {
+		mainRanks = []string{"Guild Leader", "Veteran Raider", "Officer", "Raider"}
+	}
+	secondMain := configuration.Everquest.RegexIsSecondMain
+	if secondMain == "" {
+		secondMain = "nd [Mm]ain"
+	}
+	return []RankRule{
+		{Ranks: []string{"Inactive"}, Tier: "Inactive"},
+		{Ranks: mainRanks, Alt: "no", Tier: "Main"},
+		{Note: secondMain, Alt: "yes", Tier: "Second Main"},
+		{Ranks: []string{"Recruit"}, Tier: "Recruit"},
+		{Alt: "yes", Tier: "Alt"},
+		{Ranks: []string{"Member"}, Tier: "Social"},
+	}
+}
+
+// loadRankPolicy compiles the configured rank rules
+func loadRankPolicy(rules []RankRule) error {
+	if len(rules) == 0 {
+		rules = defaultRankRules()
+	}
+	policy := make([]rankRule, 0, len(rules))
+	for i, rule := range rules {
+		tier, err := parseDKPRank(rule.Tier)
+		if err != nil {
+			return fmt.Errorf("rank rule %d: %w", i+1, err)
+		}
+		compiled := rankRule{
+			ranks: rule.Ranks,
+			alt:   strings.ToLower(rule.Alt),
+			tier:  tier,
+		}
+		if compiled.alt != "" && compiled.alt != "yes" && compiled.alt != "no" {
+			return fmt.Errorf("rank rule %d: alt must be yes, no or empty, got %s", i+1, rule.Alt)
+		}
+		if rule.Note != "" {
+			compiled.note, err = regexp.Compile(rule.Note)
+			if err != nil {
+				return fmt.Errorf("rank rule %d: %w", i+1, err)
+			}
+		}
+		policy = append(policy, compiled)
+	}
+	var alt *regexp.Regexp
+	if configuration.Everquest.RegexIsAlt != "" {
+		var err error
+		alt, err = regexp.Compile(configuration.Everquest.RegexIsAlt)
+		if err != nil {
+			return fmt.Errorf("RegexIsAlt: %w", err)
+		}
+	}
+	rankPolicy = policy
+	altMatch = alt
+	return nil
+}
+
+// isAlt checks the in game alt flag, or the public note if an alt regex is configured
+func isAlt(member *everquest.GuildMember) bool {
+	return member.Alt || (altMatch != nil && altMatch.MatchString(member.PublicNote))
+}
+
+func (r *rankRule) matches(member *everquest.GuildMember) bool {
+	if len(r.ranks) > 0 && !member.HasRank(r.ranks) {
+		return false
+	}
+	if r.note != nil && !r.note.MatchString(member.PublicNote) {
+		return false
+	}
+	switch r.alt {
+	case "yes":
+		return isAlt(member)
+	case "no":
+		return !isAlt(member)
+	}
+	return true
+}
+
+// parseDKPRank converts a tier name like "Second Main" back to a DKPRank
+func parseDKPRank(name string) (DKPRank, error) {
+	for rank := DKPRank(INACTIVE); rank <= MAIN; rank++ {
+		if sameTier(DKPRankToString(rank), name) {
+			return rank, nil
+		}
+	}
+	for i, custom := range customTierNames() {
+		if sameTier(custom, name) {
+			return DKPRank(MAIN + 1 + i), nil
+		}
+	}
+	return INACTIVE, fmt.Errorf("unknown DKP tier %q", name)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestRankPolicyCustom(t *testing.T) {
+	err := loadRankPolicy([]RankRule{
+		{Ranks: []string{"Benched"}, Tier: "Inactive"},
+		{Note: "(?i)box", Alt: "yes", Tier: "Second Main"},
+		{Ranks: []string{"Knight", "Squire"}, Alt: "no", Tier: "Main"},
+		{Ranks: []string{"Page"}, Tier: "Recruit"},
+		{Alt: "yes", Tier: "Alt"},
+		{Tier: "Social"},
+	})
+	if err != nil {
+		t.Fatalf("loadRankPolicy() error: %s", err)
+	}
+	defer loadRankPolicy(configuration.Ranks)
+	tests := []struct {
+		member everquest.GuildMember
+		want   DKPRank
+	}{
+		{everquest.GuildMember{Name: "Knightly", Rank: "Knight"}, MAIN},
+		{everquest.GuildMember{Name: "Benchy", Rank: "Benched"}, INACTIVE},
+		{everquest.GuildMember{Name: "Boxed", Rank: "Knight", Alt: true, PublicNote: "Knightly Box"}, SECONDMAIN},
+		{everquest.GuildMember{Name: "Alty", Rank: "Knight", Alt: true, PublicNote: "Knightly Alt"}, ALT},
+		{everquest.GuildMember{Name: "Pagey", Rank: "Page"}, RECRUIT},
+		{everquest.GuildMember{Name: "Friend", Rank: "Friend"}, SOCIAL},
+	}
+	for _, tt := range tests {
+		got := getDKPRank(&tt.member)
+		if got != tt.want {
+			t.Errorf("getDKPRank(%s) = %s; want %s", tt.member.Name, DKPRankToString(got), DKPRankToString(tt.want))
+		}
+	}
+}
+
+func TestRankPolicyBadTier(t *testing.T) {
+	err := loadRankPolicy([]RankRule{{Ranks: []string{"Knight"}, Tier: "Emperor"}})
+	if err == nil {
+		t.Errorf("loadRankPolicy() with unknown tier returned no error")
+	}
+}
+
+func TestParseDKPRank(t *testing.T) {
+	got, err := parseDKPRank("secondmain")
+	if err != nil || got != SECONDMAIN {
+		t.Errorf("parseDKPRank(\"secondmain\") = %d, %v; want %d", got, err, SECONDMAIN)
+	}
+}
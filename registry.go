@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Configurable is implemented by plugins that take settings from their Plugins entry
+type Configurable interface {
+	Configure(settings map[string]string) error
+}
+
+// registeredPlugins are the keys of every plugin compiled in, loaded or not
+var registeredPlugins []string
+
+// pluginProblems are config mistakes found while loading plugins, reported at startup
+var pluginProblems []string
+
+func pluginConfig(key string) (PluginConfig, bool) {
+	for _, conf := range configuration.Plugins {
+		if strings.EqualFold(conf.Name, key) {
+			return conf, true
+		}
+	}
+	return PluginConfig{}, false
+}
+
+// registerPlugin adds a plugin to Handlers unless the config leaves it out, with its configured route and settings
+func registerPlugin(key string, handler LogHandler) {
+	registeredPlugins = append(registeredPlugins, key)
+	conf, listed := pluginConfig(key)
+	if len(configuration.Plugins) > 0 && (!listed || conf.Disabled) {
+		return
+	}
+	if conf.Output != "" {
+		name := strings.ToLower(strings.TrimSpace(conf.Output))
+		if routeKnown(name) {
+			handlerRoutes[handler] = name
+		} else {
+			pluginProblems = append(pluginProblems, fmt.Sprintf("%s: unknown route %s", key, conf.Output))
+		}
+	}
+	if conf.Username != "" || conf.AvatarURL != "" {
+		pluginIdentities[handler] = WebhookIdentity{Username: conf.Username, AvatarURL: conf.AvatarURL}
+	}
+	if len(conf.Settings) > 0 {
+		if configurable, ok := handler.(Configurable); !ok {
+			pluginProblems = append(pluginProblems, fmt.Sprintf("%s: plugin has no settings", key))
+		} else if err := configurable.Configure(conf.Settings); err != nil {
+			pluginProblems = append(pluginProblems, fmt.Sprintf("%s: %s", key, err.Error()))
+		}
+	}
+	Handlers = append(Handlers, handler)
+}
+
+// checkPlugins reports Plugins entries that name no plugin or could not be applied, needs to run AFTER every plugin registered
+func checkPlugins() error {
+	problems := append([]string{}, pluginProblems...)
+	seen := make(map[string]bool)
+	for _, conf := range configuration.Plugins {
+		key := strings.ToLower(strings.TrimSpace(conf.Name))
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("%s: listed more than once", conf.Name))
+		}
+		seen[key] = true
+		known := false
+		for _, registered := range registeredPlugins {
+			if registered == key {
+				known = true
+			}
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("%s: unknown plugin, use one of %s", conf.Name, strings.Join(registeredPlugins, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("plugin config: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// withPluginConfig swaps in a Plugins config and an empty registry for a test
+func withPluginConfig(t *testing.T, plugins []PluginConfig) {
+	oldPlugins, oldHandlers, oldRegistered, oldProblems, oldRoutes := configuration.Plugins, Handlers, registeredPlugins, pluginProblems, handlerRoutes
+	configuration.Plugins, Handlers, registeredPlugins, pluginProblems, handlerRoutes = plugins, nil, nil, nil, make(map[LogHandler]string)
+	t.Cleanup(func() {
+		configuration.Plugins, Handlers, registeredPlugins, pluginProblems, handlerRoutes = oldPlugins, oldHandlers, oldRegistered, oldProblems, oldRoutes
+	})
+}
+
+func TestRegisterPluginEmptyConfigLoadsAll(t *testing.T) {
+	withPluginConfig(t, nil)
+	plug := &ParsePlugin{Plugin: Plugin{Output: PARSEOUT}}
+	registerPlugin("parse", plug)
+	got := len(Handlers)
+	want := 1
+	if got != want {
+		t.Errorf("len(Handlers) = %d, want %d", got, want)
+	}
+}
+
+func TestRegisterPluginOnlyListed(t *testing.T) {
+	withPluginConfig(t, []PluginConfig{{Name: "zone"}, {Name: "parse", Disabled: true}})
+	parse := &ParsePlugin{Plugin: Plugin{Output: PARSEOUT}}
+	registerPlugin("parse", parse)
+	linkdead := &LinkdeadPlugin{Output: RAIDOUT}
+	registerPlugin("linkdead", linkdead)
+	zone := &ZonePlugin{Output: STDOUT}
+	registerPlugin("zone", zone)
+	if len(Handlers) != 1 || Handlers[0] != zone {
+		t.Errorf("Handlers = %v, want only the zone plugin", Handlers)
+	}
+}
+
+func TestRegisterPluginOutputAndSettings(t *testing.T) {
+	withPluginConfig(t, []PluginConfig{{Name: "Parse", Output: "Investigations", Settings: map[string]string{"channel": "raid_parses", "identifier": "dps"}}})
+	plug := &ParsePlugin{Plugin: Plugin{Output: PARSEOUT}}
+	registerPlugin("parse", plug)
+	if got := routeName(plug); got != "investigations" {
+		t.Errorf("routeName(plug) = %q, want %q", got, "investigations")
+	}
+	if plug.Channel != "raid_parses" || plug.Identifier != "dps" {
+		t.Errorf("plug.Channel, plug.Identifier = %q, %q, want %q, %q", plug.Channel, plug.Identifier, "raid_parses", "dps")
+	}
+	if err := checkPlugins(); err != nil {
+		t.Errorf("checkPlugins() = %s, want nil", err)
+	}
+}
+
+func TestCheckPluginsReportsProblems(t *testing.T) {
+	withPluginConfig(t, []PluginConfig{{Name: "zone", Output: "nowhere"}, {Name: "linkdead", Settings: map[string]string{"loud": "yes"}}, {Name: "spells"}, {Name: "parse", Settings: map[string]string{"color": "red"}}})
+	zone := &ZonePlugin{Output: STDOUT}
+	registerPlugin("zone", zone)
+	linkdead := &LinkdeadPlugin{Output: RAIDOUT}
+	registerPlugin("linkdead", linkdead)
+	parse := &ParsePlugin{Plugin: Plugin{Output: PARSEOUT}}
+	registerPlugin("parse", parse)
+	err := checkPlugins()
+	if err == nil {
+		t.Fatalf("checkPlugins() = nil, want an error")
+	}
+	for _, want := range []string{"zone: unknown route nowhere", "linkdead: plugin has no settings", "spells: unknown plugin", "parse: unknown setting: color"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("checkPlugins() = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+	if got := routeName(zone); got != "stdout" {
+		t.Errorf("routeName(zone) = %q, want %q", got, "stdout")
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultRollOffTimeout = 2 * time.Minute
+
+// RollOff is a tie being settled with /rand 1000, the highest rollers take the items left
+type RollOff struct {
+	Bid      *OpenBid
+	Slots    int            // items left for the tied players
+	Players  []string       // tied players
+	Rolls    map[string]int // first roll of each player that has rolled
+	Deadline time.Time
+}
+
+// rollOffs are ties waiting on rolls, their bids are already closed but stay in the bid journal until decided
+var rollOffs []*RollOff
+
+func rollOffTimeout() time.Duration {
+	if configuration.Bids.RollOffSeconds > 0 {
+		return time.Duration(configuration.Bids.RollOffSeconds) * time.Second
+	}
+	return defaultRollOffTimeout
+}
+
+// startRollOff holds a closed bid until the tied players have rolled
+func startRollOff(b *OpenBid, ties map[string]interface{}) {
+	var winners int
+	var players []string
+	for _, bidder := range b.Bidders {
+		if !bidder.WonOrTied {
+			continue
+		}
+		if _, ok := ties[bidder.Player.Name]; ok {
+			players = append(players, bidder.Player.Name)
+		} else {
+			winners++
+		}
+	}
+	rollOffs = append(rollOffs, &RollOff{
+		Bid:      b,
+		Slots:    b.Quantity - winners,
+		Players:  players,
+		Rolls:    make(map[string]int),
+		Deadline: getTime().Add(rollOffTimeout()),
+	})
+	Info.Printf("Roll off started for %s (x%d) between %s", b.Item.Name, b.Quantity, strings.Join(players, ", "))
+}
+
+// recordRoll applies a /rand 1000 to the roll off the player is in, returns false if they are not rolling for anything
+func recordRoll(player string, result int, out io.Writer) bool {
+	for _, r := range rollOffs {
+		if !r.has(player) {
+			continue
+		}
+		if _, ok := r.Rolls[player]; ok {
+			continue // only the first roll counts
+		}
+		r.Rolls[player] = result
+		if len(r.Rolls) == len(r.Players) {
+			r.resolve(out)
+		} else {
+			saveRollOffs()
+		}
+		return true
+	}
+	return false
+}
+
+// checkRollOffs settles roll offs whose time ran out, players that did not roll forfeit
+func checkRollOffs(now time.Time, out io.Writer) {
+	for _, r := range append([]*RollOff{}, rollOffs...) { // resolve changes rollOffs
+		if now.After(r.Deadline) {
+			r.resolve(out)
+		}
+	}
+}
+
+// saveRollOffs journals the roll offs with the open bids, the bid plugin may be disabled
+func saveRollOffs() {
+	if plug := getBidPlugin(); plug != nil {
+		plug.saveBids()
+	}
+}
+
+func (r *RollOff) has(player string) bool {
+	for _, name := range r.Players {
+		if name == player {
+			return true
+		}
+	}
+	return false
+}
+
+// setResult marks a tied player as a winner or loser of the roll off
+func (r *RollOff) setResult(player string, won bool, rule string) {
+	for _, bidder := range r.Bid.Bidders {
+		if bidder.Player.Name == player {
+			bidder.WonOrTied = won
+			bidder.addRule(rule)
+		}
+	}
+}
+
+func (r *RollOff) remove() {
+	for i, active := range rollOffs {
+		if active == r {
+			rollOffs = append(rollOffs[:i], rollOffs[i+1:]...)
+			break
+		}
+	}
+	for _, player := range r.Players {
+		for _, name := range needsRolled {
+			if name == player {
+				removeRollerFromRoll(player)
+				break
+			}
+		}
+	}
+}
+
+// resolve awards the items to the highest rollers, rolling again if the last item is tied
+func (r *RollOff) resolve(out io.Writer) {
+	r.remove()
+	var rolled []string
+	for _, player := range r.Players {
+		if _, ok := r.Rolls[player]; ok {
+			rolled = append(rolled, player)
+		} else {
+			r.setResult(player, false, "did not roll in time")
+		}
+	}
+	sort.SliceStable(rolled, func(i, j int) bool { return r.Rolls[rolled[i]] > r.Rolls[rolled[j]] })
+	if len(rolled) > r.Slots && r.Slots > 0 {
+		cutoff := r.Rolls[rolled[r.Slots-1]]
+		var rerolls []string
+		slots := r.Slots
+		for _, player := range rolled {
+			roll := r.Rolls[player]
+			switch {
+			case roll > cutoff:
+				slots--
+				r.setResult(player, true, fmt.Sprintf("won roll off with %d", roll))
+			case roll == cutoff:
+				rerolls = append(rerolls, player)
+			default:
+				r.setResult(player, false, fmt.Sprintf("lost roll off with %d", roll))
+			}
+		}
+		if len(rerolls) > slots {
+			rollOffs = append(rollOffs, &RollOff{
+				Bid:      r.Bid,
+				Slots:    slots,
+				Players:  rerolls,
+				Rolls:    make(map[string]int),
+				Deadline: getTime().Add(rollOffTimeout()),
+			})
+			needsRolled = append(needsRolled, rerolls...)
+			saveRollOffs()
+			reroll := fmt.Sprintf("```diff\n- %s tied again with %d, /rand 1000 needed for %s from %s```", strings.Join(rerolls, ", "), cutoff, r.Bid.Item.Name, strings.Join(rerolls, ", "))
+			fmt.Fprintf(out, "%s", reroll)
+			err := updateMessage(configuration.Discord.LootChannelID, r.Bid.MessageID, reroll)
+			if err != nil {
+				Err.Println(err)
+			}
+			return
+		}
+		for _, player := range rerolls {
+			r.setResult(player, true, fmt.Sprintf("won roll off with %d", cutoff))
+		}
+	} else {
+		for _, player := range rolled {
+			r.setResult(player, true, fmt.Sprintf("won roll off with %d", r.Rolls[player]))
+		}
+	}
+	winners := strings.Join(r.Bid.GetWinnerNames(), ", ")
+	if winners == "" {
+		winners = "Rot"
+	}
+	fmt.Fprintf(out, "```ini\n[%s won the roll off for %s]\n```", winners, r.Bid.Item.Name)
+	r.Bid.AnnounceWinners(true)
+	saveRollOffs()
+}
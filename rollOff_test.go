@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func rollOffTestBid(names ...string) *OpenBid {
+	rollOffs = nil // ties from other tests are still waiting on rolls
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	bid := &OpenBid{Item: item, Quantity: 1}
+	ties := make(map[string]interface{})
+	for _, name := range names {
+		Roster[name] = &DKPHolder{GuildMember: everquest.GuildMember{Name: name}, DKP: 1000, DKPRank: MAIN}
+		bid.Bidders = append(bid.Bidders, &Bidder{Player: Roster[name], AttemptedBid: 100, Bid: 100, WonOrTied: true})
+		ties[name] = nil
+	}
+	startRollOff(bid, ties)
+	return bid
+}
+
+func TestRollOffHighestWins(t *testing.T) {
+	bid := rollOffTestBid("Rollera", "Rollerb")
+	var b bytes.Buffer
+	recordRoll("Rollera", 200, &b)
+	if got := bid.GetWinnerNames(); len(got) != 2 {
+		t.Errorf("winners decided before everyone rolled: %v", got)
+	}
+	recordRoll("Rollerb", 900, &b)
+	got := bid.GetWinnerNames()
+	if len(got) != 1 || got[0] != "Rollerb" {
+		t.Errorf("GetWinnerNames() = %v; want [Rollerb]", got)
+	}
+	if len(rollOffs) != 0 {
+		t.Errorf("%d roll offs left open", len(rollOffs))
+	}
+}
+
+func TestRollOffTimeout(t *testing.T) {
+	bid := rollOffTestBid("Rollerc", "Rollerd")
+	var b bytes.Buffer
+	recordRoll("Rollerc", 5, &b)
+	checkRollOffs(getTime().Add(rollOffTimeout()+time.Second), &b)
+	got := bid.GetWinnerNames()
+	if len(got) != 1 || got[0] != "Rollerc" {
+		t.Errorf("GetWinnerNames() = %v; want [Rollerc]", got)
+	}
+}
+
+func TestRollOffReroll(t *testing.T) {
+	bid := rollOffTestBid("Rollere", "Rollerf")
+	var b bytes.Buffer
+	recordRoll("Rollere", 500, &b)
+	recordRoll("Rollerf", 500, &b)
+	if len(rollOffs) != 1 {
+		t.Fatalf("tied rolls started %d roll offs; want 1", len(rollOffs))
+	}
+	recordRoll("Rollerf", 600, &b)
+	recordRoll("Rollere", 100, &b)
+	got := bid.GetWinnerNames()
+	if len(got) != 1 || got[0] != "Rollerf" {
+		t.Errorf("GetWinnerNames() = %v; want [Rollerf]", got)
+	}
+}
@@ -3,17 +3,13 @@ package main
 import (
 	"fmt"
 	"io"
-	"regexp"
-	"strconv"
-
-	everquest "github.com/Mortimus/goEverquest"
+	"time"
 )
 
 var needsRolled []string
 
 type RollPlugin struct {
 	Plugin
-	RollMatch *regexp.Regexp
 }
 
 func init() {
@@ -22,36 +18,33 @@ func init() {
 	plug.Author = "Mortimus"
 	plug.Version = "1.0.0"
 	plug.Output = BIDOUT
-	plug.RollMatch, _ = regexp.Compile(configuration.Everquest.RegexRoll)
-	Handlers = append(Handlers, plug)
+	registerPlugin("roll", plug)
+}
+
+func (p *RollPlugin) Subscriptions() []EventType {
+	return []EventType{EventRoll}
 }
 
-// Handle for RollPlugin sends a message if a parse was pasted to the parse channel
-func (p *RollPlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if msg.Channel == "system" {
-		match := p.RollMatch.FindStringSubmatch(msg.Msg)
-		if len(match) > 0 {
-			player := match[1]
-			if player == "You" {
-				player = getPlayerName(configuration.Everquest.LogPath)
-			}
-			low, _ := strconv.Atoi(match[2])
-			high, _ := strconv.Atoi(match[3])
-			result, _ := strconv.Atoi(match[4])
-			if low != 0 || high != 1000 {
-				return
-			}
-			for _, rollers := range needsRolled {
-				if rollers == player {
-					fmt.Fprintf(out, "```ini\n[%s rolled a %d]\n```", player, result)
-					removeRollerFromRoll(player)
-					return
-				}
-			}
+func (p *RollPlugin) HandleEvent(event Event, out io.Writer) {
+	e, ok := event.(RollEvent)
+	if !ok || e.Low != 0 || e.High != 1000 {
+		return
+	}
+	for _, rollers := range needsRolled {
+		if rollers == e.Player {
+			fmt.Fprintf(out, "```ini\n[%s rolled a %d]\n```", e.Player, e.Result)
+			removeRollerFromRoll(e.Player)
+			recordRoll(e.Player, e.Result, out)
+			return
 		}
 	}
 }
 
+// Tick times out roll offs when no log lines are coming in
+func (p *RollPlugin) Tick(now time.Time, out io.Writer) {
+	checkRollOffs(now, out)
+}
+
 func removeRollerFromRoll(player string) {
 	var PlayerPos int
 	for pos, name := range needsRolled {
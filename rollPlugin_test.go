@@ -2,7 +2,6 @@ package main
 
 import (
 	"bytes"
-	"regexp"
 	"testing"
 	"time"
 
@@ -18,12 +17,11 @@ func TestRoll(t *testing.T) {
 	msg.T = time.Now()
 	var b bytes.Buffer
 	needsRolled = append(needsRolled, "Mortimus")
-	plug.RollMatch, _ = regexp.Compile(`\*\*A Magic Die is rolled by (\w+). It could have been any number from (\d+) to (\d+), but this time it turned up a (\d+).`)
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	got := b.String()
 	want := "```ini\n[Mortimus rolled a 894]\n```"
 	if got != want {
-		t.Errorf("plug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(plug, msg, &b) = %q, want %q", got, want)
 	}
 	needsRolled = []string{}
 }
@@ -37,12 +35,11 @@ func TestWrongLowRoll(t *testing.T) {
 	msg.T = time.Now()
 	var b bytes.Buffer
 	needsRolled = append(needsRolled, "Mortimus")
-	plug.RollMatch, _ = regexp.Compile(`\*\*A Magic Die is rolled by (\w+). It could have been any number from (\d+) to (\d+), but this time it turned up a (\d+).`)
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("plug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(plug, msg, &b) = %q, want %q", got, want)
 	}
 	needsRolled = []string{}
 }
@@ -56,12 +53,11 @@ func TestWrongHighRoll(t *testing.T) {
 	msg.T = time.Now()
 	var b bytes.Buffer
 	needsRolled = append(needsRolled, "Mortimus")
-	plug.RollMatch, _ = regexp.Compile(`\*\*A Magic Die is rolled by (\w+). It could have been any number from (\d+) to (\d+), but this time it turned up a (\d+).`)
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("plug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(plug, msg, &b) = %q, want %q", got, want)
 	}
 	needsRolled = []string{}
 }
@@ -75,12 +71,11 @@ func TestWrongLowHighRoll(t *testing.T) {
 	msg.T = time.Now()
 	var b bytes.Buffer
 	needsRolled = append(needsRolled, "Mortimus")
-	plug.RollMatch, _ = regexp.Compile(`\*\*A Magic Die is rolled by (\w+). It could have been any number from (\d+) to (\d+), but this time it turned up a (\d+).`)
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("plug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(plug, msg, &b) = %q, want %q", got, want)
 	}
 	needsRolled = []string{}
 }
@@ -94,12 +89,11 @@ func TestMysteryRoll(t *testing.T) {
 	msg.T = time.Now()
 	var b bytes.Buffer
 	needsRolled = append(needsRolled, "Penelo")
-	plug.RollMatch, _ = regexp.Compile(`\*\*A Magic Die is rolled by (\w+). It could have been any number from (\d+) to (\d+), but this time it turned up a (\d+).`)
-	plug.Handle(msg, &b)
+	publishLog(plug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("plug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(plug, msg, &b) = %q, want %q", got, want)
 	}
 	needsRolled = []string{}
 }
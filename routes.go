@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// routeNames are the routes each plugin output channel writes to unless its Plugins entry picks another
+var routeNames = map[int]string{
+	TESTOUT:        "test",
+	STDOUT:         "stdout",
+	BIDOUT:         "bids",
+	INVESTIGATEOUT: "investigations",
+	RAIDOUT:        "raid",
+	SPELLOUT:       "spells",
+	FLAGOUT:        "flags",
+	PARSEOUT:       "parses",
+}
+
+// routes are the writers plugins send their output to, by route name
+var routes map[string]*RouteWriter
+
+// handlerRoutes are plugins sent to a route other than their default
+var handlerRoutes = make(map[LogHandler]string)
+
+// RouteWriter sends everything written to it to each of its sinks
+type RouteWriter struct {
+	Name  string
+	Sinks []io.Writer
+}
+
+// Write for RouteWriter writes to every sink even if one fails, returning the first error
+func (rw *RouteWriter) Write(p []byte) (n int, err error) {
+	for _, sink := range rw.Sinks {
+		_, sinkErr := sink.Write(p)
+		if sinkErr != nil {
+			Err.Printf("Error writing to route %s: %s", rw.Name, sinkErr.Error())
+			if err == nil {
+				err = sinkErr
+			}
+		}
+	}
+	return len(p), err
+}
+
+// FileSender is a sink that can take file attachments, like raid and guild dumps
+type FileSender interface {
+	SendFile(name string, r io.Reader) error
+}
+
+// SendFile for RouteWriter uploads a file to every sink that takes files, returning the first error
+func (rw *RouteWriter) SendFile(name string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, sink := range rw.Sinks {
+		sender, ok := sink.(FileSender)
+		if !ok {
+			continue
+		}
+		sendErr := sender.SendFile(name, bytes.NewReader(data))
+		if sendErr != nil {
+			Err.Printf("Error sending %s to route %s: %s", name, rw.Name, sendErr.Error())
+			if err == nil {
+				err = sendErr
+			}
+		}
+	}
+	return err
+}
+
+// sendFile uploads a file to a route, dumps go to the dumps route
+func sendFile(route string, name string, r io.Reader) {
+	writer, ok := routes[route]
+	if !ok {
+		Err.Printf("Cannot send %s, no route named %s", name, route)
+		return
+	}
+	err := writer.SendFile(name, r)
+	if err != nil {
+		Err.Printf("Error sending %s: %s", name, err.Error())
+	}
+}
+
+// FileWriter appends to a file, it is opened on every write so it can be rotated or removed while running
+type FileWriter struct {
+	Path string
+}
+
+func (fw *FileWriter) Write(p []byte) (n int, err error) {
+	file, err := os.OpenFile(fw.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return file.Write(p)
+}
+
+// parseSink turns a sink from the config into a writer: stdout, discord:<channel id>, webhook:<url> or file:<path>
+func parseSink(sink string) (io.Writer, error) {
+	sink = strings.TrimSpace(sink)
+	kind, target := sink, ""
+	if i := strings.Index(sink, ":"); i >= 0 {
+		kind, target = sink[:i], strings.TrimSpace(sink[i+1:])
+	}
+	switch strings.ToLower(kind) {
+	case "stdout":
+		return os.Stdout, nil
+	case "discord":
+		if target == "" {
+			return nil, errors.New("discord sink needs a channel id: " + sink)
+		}
+		return &DiscordWriter{Channel: target}, nil
+	case "webhook":
+		if !strings.HasPrefix(target, "https://") && !strings.HasPrefix(target, "http://") {
+			return nil, errors.New("webhook sink needs the webhook url: " + sink)
+		}
+		return &WebhookWriter{URL: target}, nil
+	case "file":
+		if target == "" {
+			return nil, errors.New("file sink needs a path: " + sink)
+		}
+		return &FileWriter{Path: target}, nil
+	}
+	return nil, errors.New("unknown route sink: " + sink)
+}
+
+// validateRoutes checks the configured routes before anything is written to them
+func validateRoutes(configured []Route) error {
+	seen := make(map[string]bool)
+	for _, route := range configured {
+		name := strings.ToLower(strings.TrimSpace(route.Name))
+		if name == "" {
+			return errors.New("route needs a name")
+		}
+		if seen[name] {
+			return errors.New("route listed more than once: " + route.Name)
+		}
+		seen[name] = true
+		if len(route.Sinks) == 0 {
+			return errors.New("route has no sinks: " + route.Name)
+		}
+		for _, sink := range route.Sinks {
+			if _, err := parseSink(sink); err != nil {
+				return fmt.Errorf("route %s: %s", route.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// buildRoutes makes the default route for every output channel, then adds or replaces the configured routes
+func buildRoutes(configured []Route) map[string]*RouteWriter {
+	built := map[string]*RouteWriter{
+		"test":           {Name: "test", Sinks: []io.Writer{os.Stdout}},
+		"stdout":         {Name: "stdout", Sinks: []io.Writer{os.Stdout}},
+		"bids":           {Name: "bids", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.LootChannelID}}},
+		"investigations": {Name: "investigations", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.InvestigationChannelID}}},
+		"raid":           {Name: "raid", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.RaidDumpChannelID}}},
+		"spells":         {Name: "spells", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.SpellDumpChannelID}}},
+		"flags":          {Name: "flags", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.FlagChannelID}}},
+		"parses":         {Name: "parses", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.ParseChannelID}}},
+		"dumps":          {Name: "dumps", Sinks: []io.Writer{&DiscordWriter{Channel: configuration.Discord.RaidDumpChannelID}}},
+	}
+	for _, route := range configured {
+		name := strings.ToLower(strings.TrimSpace(route.Name))
+		writer := &RouteWriter{Name: name}
+		for _, sink := range route.Sinks {
+			w, err := parseSink(sink)
+			if err != nil {
+				Err.Printf("Skipping sink on route %s: %s", name, err.Error())
+				continue
+			}
+			writer.Sinks = append(writer.Sinks, w)
+		}
+		built[name] = writer
+	}
+	return built
+}
+
+// routeKnown is true for the default routes and the configured ones
+func routeKnown(name string) bool {
+	if name == "dumps" {
+		return true // file uploads only, no plugin writes to it by default
+	}
+	for _, known := range routeNames {
+		if known == name {
+			return true
+		}
+	}
+	for _, route := range configuration.Routes {
+		if strings.EqualFold(strings.TrimSpace(route.Name), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeName is the route a plugin writes to
+func routeName(handler LogHandler) string {
+	if name, ok := handlerRoutes[handler]; ok {
+		return name
+	}
+	return routeNames[handler.OutputChannel()]
+}
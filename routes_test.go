@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (fw failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("sink down")
+}
+
+func TestRouteWriterFansOut(t *testing.T) {
+	var raid, officers bytes.Buffer
+	route := &RouteWriter{Name: "raid", Sinks: []io.Writer{&raid, failingWriter{}, &officers}}
+	_, err := route.Write([]byte("Vex Thal boss down\n"))
+	if err == nil {
+		t.Errorf("route.Write() error = nil, want the failing sink's error")
+	}
+	for _, got := range []string{raid.String(), officers.String()} {
+		want := "Vex Thal boss down\n"
+		if got != want {
+			t.Errorf("sink got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		sink    string
+		wantErr bool
+	}{
+		{"stdout", false},
+		{"Discord: 123456", false},
+		{"file:logs/raid.log", false},
+		{"webhook:https://discord.com/api/webhooks/1/abc", false},
+		{"webhook:discord", true},
+		{"discord:", true},
+		{"file", true},
+		{"carrier pigeon", true},
+	}
+	for _, tt := range tests {
+		_, err := parseSink(tt.sink)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSink(%q) error = %v, want error %t", tt.sink, err, tt.wantErr)
+		}
+	}
+	w, _ := parseSink("Discord: 123456")
+	if dw, ok := w.(*DiscordWriter); !ok || dw.Channel != "123456" {
+		t.Errorf("parseSink(%q) = %#v, want a DiscordWriter for 123456", "Discord: 123456", w)
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	tests := []struct {
+		routes  []Route
+		wantErr bool
+	}{
+		{[]Route{{Name: "officers", Sinks: []string{"discord:1", "stdout"}}}, false},
+		{[]Route{{Name: "", Sinks: []string{"stdout"}}}, true},
+		{[]Route{{Name: "officers"}}, true},
+		{[]Route{{Name: "officers", Sinks: []string{"stdout"}}, {Name: "Officers", Sinks: []string{"stdout"}}}, true},
+		{[]Route{{Name: "officers", Sinks: []string{"irc:#raid"}}}, true},
+	}
+	for i, tt := range tests {
+		err := validateRoutes(tt.routes)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateRoutes(test %d) error = %v, want error %t", i, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildRoutesReplacesDefault(t *testing.T) {
+	built := buildRoutes([]Route{{Name: "Raid", Sinks: []string{"discord:1", "discord:2"}}, {Name: "officers", Sinks: []string{"stdout"}}})
+	got := len(built["raid"].Sinks)
+	want := 2
+	if got != want {
+		t.Errorf("len(built[raid].Sinks) = %d, want %d", got, want)
+	}
+	if _, ok := built["officers"]; !ok {
+		t.Errorf("built[officers] missing")
+	}
+	if _, ok := built["bids"]; !ok {
+		t.Errorf("built[bids] missing, defaults should stay")
+	}
+}
+
+func TestFileWriterAppends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fw := &FileWriter{Path: filepath.Join(dir, "raid.log")}
+	fw.Write([]byte("first\n"))
+	fw.Write([]byte("second\n"))
+	data, _ := ioutil.ReadFile(fw.Path)
+	got := string(data)
+	want := "first\nsecond\n"
+	if got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
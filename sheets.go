@@ -2,18 +2,53 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	everquest "github.com/Mortimus/goEverquest"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
 	"google.golang.org/api/sheets/v4"
 )
 
 // srv is the global to connect to google sheets
 var srv *sheets.Service
 
+// connectSheets authorizes against google and sets up srv
+func connectSheets() {
+	gtoken := &Gtoken{
+		Installed: Inst{
+			ClientID:                configuration.Google.ClientID,
+			ProjectID:               configuration.Google.ProjectID,
+			AuthURI:                 configuration.Google.AuthURI,
+			TokenURI:                configuration.Google.TokenURI,
+			AuthProviderx509CertURL: configuration.Google.AuthProviderx509CertURL,
+			ClientSecret:            configuration.Google.ClientSecret,
+			RedirectURIs:            configuration.Google.RedirectURIs,
+		},
+	}
+	Info.Printf("Marshalling gToken: %+v", gtoken)
+	bToken, err := json.Marshal(gtoken)
+	if err != nil {
+		Err.Fatalf("error marshalling gtoken")
+	}
+
+	// If modifying these scopes, delete your previously saved token.json.
+	config, err := google.ConfigFromJSON(bToken, "https://www.googleapis.com/auth/spreadsheets")
+	if err != nil {
+		Err.Fatalf("Unable to parse client secret file to config: %v", err)
+	}
+	client := getClient(config)
+
+	srv, err = sheets.New(client)
+	if err != nil {
+		Err.Fatalf("Unable retrieve Sheets client: %v", err)
+	}
+}
+
 // Retrieve a token, saves the token, then returns the generated client.
 func getClient(config *oauth2.Config) *http.Client {
 	// The file token.json stores the user's access and refresh tokens, and is
@@ -135,8 +170,54 @@ type Gtoken struct {
 // 	}
 // }
 
+// SheetsStore is the google sheets backend for the DKP ledger
+type SheetsStore struct{}
+
+func (s *SheetsStore) LoadLedger() ([][]string, error) {
+	return s.get(configuration.Sheets.RawSheetURL, configuration.Sheets.RawSheetName)
+}
+
+func (s *SheetsStore) LoadBosses() ([][]string, error) {
+	return s.get(configuration.Sheets.RawSheetURL, configuration.Sheets.BossesSheetName)
+}
+
+func (s *SheetsStore) LoadSpellNeeds(class string) ([][]string, error) {
+	return s.get(configuration.Sheets.SpellSheetURL, class)
+}
+
+func (s *SheetsStore) AppendSpent(rows [][]string) error {
+	var values [][]interface{}
+	for _, row := range rows {
+		var value []interface{}
+		for _, record := range row {
+			value = append(value, record)
+		}
+		values = append(values, value)
+	}
+	_, err := srv.Spreadsheets.Values.Append(configuration.Sheets.RawSheetURL, configuration.Sheets.RawSheetName, &sheets.ValueRange{Values: values}).ValueInputOption("USER_ENTERED").Do()
+	return err
+}
+
+func (s *SheetsStore) get(spreadsheetID, readRange string) ([][]string, error) {
+	if srv == nil {
+		return nil, errors.New("google sheets is not connected")
+	}
+	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for _, row := range resp.Values {
+		var rec []string
+		for _, record := range row {
+			rec = append(rec, fmt.Sprintf("%s", record))
+		}
+		rows = append(rows, rec)
+	}
+	return rows, nil
+}
+
 func findWhoNeedsSpell(s everquest.Spell) []string {
-	spreadsheetID := configuration.Sheets.SpellSheetURL
 	classes := s.GetClasses()
 	var players []string
 	for _, class := range classes {
@@ -144,18 +225,18 @@ func findWhoNeedsSpell(s everquest.Spell) []string {
 			continue
 		}
 		Info.Printf("Finding who from class %s needs %s\n", class, s.Name)
-		resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, class).Do()
+		rows, err := dkpStore.LoadSpellNeeds(class)
 		if err != nil {
 			Err.Printf("Unable to retrieve data from sheet: %v", err)
 			return nil
 		}
 
-		if len(resp.Values) == 0 {
-			Err.Printf("Cannot read spell sheet: %v", resp)
+		if len(rows) == 0 {
+			Err.Printf("Cannot read spell sheet for %s", class)
 			// log.Println("No data found.")
 		} else {
 			// var lastClass string
-			for i, row := range resp.Values {
+			for i, row := range rows {
 				// fmt.Printf("I: %d Config: %d\n", i, configuration.SpellSheetDataRowStart)
 				if i < configuration.Sheets.SpellSheetDataRowStart-1 {
 					continue
@@ -164,13 +245,12 @@ func findWhoNeedsSpell(s everquest.Spell) []string {
 				if len(row) <= configuration.Sheets.SpellSheetSpellCol {
 					continue
 				}
-				spellName := fmt.Sprintf("%s", row[configuration.Sheets.SpellSheetSpellCol])
+				spellName := row[configuration.Sheets.SpellSheetSpellCol]
 				if "Spell: "+s.Name == spellName || strings.Replace(s.Name, "Ancient ", "Ancient: ", 1) == spellName { // Ancients are dumb
 					// fmt.Printf("h: %d data: %s\n", configuration.SpellSheetPlayerStartCol, row[configuration.SpellSheetPlayerStartCol])
 					for h := configuration.Sheets.SpellSheetPlayerStartCol; h < len(row); h++ {
-						rowString := fmt.Sprintf("%s", row[h])
-						if rowString == "FALSE" {
-							player := fmt.Sprintf("%s", resp.Values[configuration.Sheets.SpellSheetPlayerRow][h])
+						if row[h] == "FALSE" {
+							player := cell(rows[configuration.Sheets.SpellSheetPlayerRow], h)
 							players = append(players, player)
 							Info.Printf("Player: %s needs %s\n", player, spellName)
 						}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+// queueTell writes a /tell for an in game macro or helper to send, one command per line
+func queueTell(player string, message string) {
+	if configuration.Everquest.TellQueuePath == "" || player == "" || player == "You" || player == getPlayerName(configuration.Everquest.LogPath) {
+		return
+	}
+	Info.Printf("Tell to %s: %s", player, message)
+	file, err := os.OpenFile(configuration.Everquest.TellQueuePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		Err.Printf("Error opening tell queue: %s", err.Error())
+		return
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "/tell %s %s\n", player, message)
+	if err != nil {
+		Err.Printf("Error writing tell queue: %s", err.Error())
+	}
+}
+
+// ackBid tells a bidder what the bot understood, the bid that will count and anything wrong with it
+func ackBid(b *OpenBid, player string, amount int) {
+	if reply := bidReply(b, player, amount); reply != "" {
+		queueTell(player, reply)
+	}
+}
+
+// ackProxyBid tells an officer what the bot understood from a bid they placed for someone else
+func ackProxyBid(b *OpenBid, proxy string, player string, amount int) {
+	if reply := bidReply(b, player, amount); reply != "" {
+		queueTell(proxy, fmt.Sprintf("For %s: %s", player, reply))
+	}
+}
+
+// bidReply describes a player's bid as it will count, empty when there is nothing to say
+func bidReply(b *OpenBid, player string, amount int) string {
+	pos := b.FindBid(player)
+	if pos < 0 {
+		if amount > 0 && b.isClassLocked() {
+			return fmt.Sprintf("%s is only for classes that can use it, bid rejected", b.Item.Name)
+		}
+		return fmt.Sprintf("Bid on %s cancelled", b.Item.Name)
+	}
+	if b.Bidders[pos].AttemptedBid <= 0 {
+		return fmt.Sprintf("Bid on %s cancelled", b.Item.Name)
+	}
+	if _, ok := Roster[getMain(&b.Bidders[pos].Player.GuildMember)]; !ok {
+		return "" // cannot work out their DKP
+	}
+	if b.isFreeRoll() {
+		return fmt.Sprintf("Entered in the free roll for %s", b.Item.Name)
+	}
+	reply := fmt.Sprintf("Bid received: %s %d", b.Item.Name, amount)
+	var warnings []string
+	if rosterDKPLoaded { // balances read 0 until the ledger is loaded, don't warn about them
+		// Work out the bid on a copy, the real one is applied when bids close
+		preview := *b.Bidders[pos]
+		holder := *preview.Player
+		preview.Player = &holder
+		b.applyDKP(&preview)
+		reply = fmt.Sprintf("Bid received: %s %d", b.Item.Name, preview.AttemptedBid)
+		if preview.Bid != amount {
+			reply += fmt.Sprintf(", counts as %d", preview.Bid)
+		}
+		if amount > preview.Player.DKP {
+			warnings = append(warnings, fmt.Sprintf("you only have %d DKP", preview.Player.DKP))
+		}
+	}
+	if configuration.Bids.Increments > 0 && amount%configuration.Bids.Increments != 0 {
+		warnings = append(warnings, fmt.Sprintf("bids go up in %d", configuration.Bids.Increments))
+	}
+	if amount < b.minimumBid() {
+		warnings = append(warnings, fmt.Sprintf("minimum bid is %d", b.minimumBid()))
+	}
+	if len(warnings) > 0 {
+		reply += " - " + strings.Join(warnings, ", ")
+	}
+	return reply
+}
+
+// bidLike is a tell shaped like a bid, some words then the amount with maybe dkp or 2nd main after it
+var bidLike = regexp.MustCompile(`(?i)^.*[a-z].*\s\d+\s*(?:dkp)?\s*(?:2nd(?:\s+main)?)?\s*$`)
+
+// ackNoMatch tells a bidder their tell did not match anything open for bids, other tells with numbers are left alone
+func ackNoMatch(p *BidPlugin, msg *everquest.EqLog) {
+	if len(p.Bids) == 0 || !bidLike.MatchString(strings.TrimSpace(msg.Msg)) {
+		return
+	}
+	if link := getLinkPlugin(); link != nil && link.LinkMatch.MatchString(strings.TrimSpace(msg.Msg)) {
+		return // account link codes are not bids
+	}
+	var open []string
+	for _, bid := range p.Bids {
+		open = append(open, bid.Item.Name)
+	}
+	sort.Strings(open)
+	queueTell(msg.Source, fmt.Sprintf("No open bid matched your tell, open: %s", strings.Join(open, ", ")))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestAckBidOverBalance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tells.txt")
+	configuration.Everquest.TellQueuePath = path
+	defer func() { configuration.Everquest.TellQueuePath = "" }()
+	rosterDKPLoaded = true
+	defer func() { rosterDKPLoaded = false }()
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	Roster["Ackbidder"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Ackbidder"}, DKP: 100, DKPRank: MAIN}
+	bid := &OpenBid{Item: item, Quantity: 1}
+	bid.AddBid(*Roster["Ackbidder"], 500, everquest.EqLog{})
+	ackBid(bid, "Ackbidder", 500)
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(file)
+	if !strings.HasPrefix(got, "/tell Ackbidder Bid received: Cloth Cap 500, counts as 100") {
+		t.Errorf("ackBid() queued %q; want the effective bid of 100", got)
+	}
+	if !strings.Contains(got, "you only have 100 DKP") {
+		t.Errorf("ackBid() queued %q; want a balance warning", got)
+	}
+}
+
+func TestBidReplyBeforeDKPLoaded(t *testing.T) {
+	rosterDKPLoaded = false
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	Roster["Earlybidder"] = &DKPHolder{GuildMember: everquest.GuildMember{Name: "Earlybidder"}, DKPRank: MAIN}
+	bid := &OpenBid{Item: item, Quantity: 1}
+	bid.AddBid(*Roster["Earlybidder"], 500, everquest.EqLog{})
+	got := bidReply(bid, "Earlybidder", 500)
+	want := "Bid received: Cloth Cap 500"
+	if got != want {
+		t.Errorf("bidReply() = %q; want %q", got, want)
+	}
+}
+
+func TestBidLike(t *testing.T) {
+	tests := []struct {
+		tell string
+		want bool
+	}{
+		{"Cloth Cap 500", true},
+		{"Cloth Cap 50 2nd", true},
+		{"Cloth Cap for Mortimus 50", true},
+		{"cap 50 dkp", true},
+		{"can I get an invite in 5 min", false},
+		{"2", false},
+		{"brb 5 minutes", false},
+	}
+	for _, tt := range tests {
+		got := bidLike.MatchString(tt.tell)
+		if got != tt.want {
+			t.Errorf("bidLike.MatchString(%q) = %t; want %t", tt.tell, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Tier is a bidding tier, bidders in a higher priority tier win over lower ones regardless of bid
+type Tier struct {
+	Name     string `comment:"Tier name, Main, Second Main, Recruit, Alt, Social and Inactive are built in, any other name adds a tier for Ranks rules"`
+	Priority int    `comment:"Higher priority tiers win over lower ones regardless of bid, equal priorities bid against each other"`
+	MaxBid   int    `comment:"Most a bidder in this tier can spend on one item, 0 for no cap"`
+	CountsAs string `comment:"Tier this one bids as, e.g. Second Main counting as Main, empty for itself"`
+}
+
+// getTiers returns the configured tier table, or the original ladder when none is configured
+func getTiers() []Tier {
+	if len(configuration.Tiers) > 0 {
+		return configuration.Tiers
+	}
+	secondMain := Tier{Name: "Second Main", Priority: 4}
+	if configuration.Bids.SecondMainsBidAsMains {
+		secondMain.CountsAs = "Main"
+		secondMain.MaxBid = configuration.Bids.SecondMainAsMainMaxBid
+	}
+	return []Tier{
+		{Name: "Main", Priority: 5},
+		secondMain,
+		{Name: "Recruit", Priority: 3},
+		{Name: "Alt", Priority: 2},
+		{Name: "Social", Priority: 1},
+		{Name: "Inactive", Priority: 0},
+	}
+}
+
+// validateTiers checks every CountsAs points at a tier in the table
+func validateTiers(tiers []Tier) error {
+	for _, tier := range tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("tier with priority %d has no name", tier.Priority)
+		}
+		if tier.CountsAs == "" {
+			continue
+		}
+		if _, ok := findTier(tiers, tier.CountsAs); !ok {
+			return fmt.Errorf("tier %s counts as unknown tier %s", tier.Name, tier.CountsAs)
+		}
+	}
+	return nil
+}
+
+// customTierNames are the configured tiers that are not built in, they are given DKPRanks after MAIN in config order
+func customTierNames() []string {
+	var names []string
+	for _, tier := range configuration.Tiers {
+		if !isBuiltinTier(tier.Name) {
+			names = append(names, tier.Name)
+		}
+	}
+	return names
+}
+
+func isBuiltinTier(name string) bool {
+	for rank := DKPRank(INACTIVE); rank <= MAIN; rank++ {
+		if sameTier(DKPRankToString(rank), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTier compares tier names ignoring case and spaces, so SecondMain and Second Main match
+func sameTier(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, " ", ""), strings.ReplaceAll(b, " ", ""))
+}
+
+func findTier(tiers []Tier, name string) (Tier, bool) {
+	for _, tier := range tiers {
+		if sameTier(tier.Name, name) {
+			return tier, true
+		}
+	}
+	return Tier{}, false
+}
+
+// getTier returns the tier for a rank, ranks missing from the table sort below every tier
+func getTier(rank DKPRank) Tier {
+	name := DKPRankToString(rank)
+	if tier, ok := findTier(getTiers(), name); ok {
+		return tier
+	}
+	return Tier{Name: name, Priority: math.MinInt32}
+}
+
+// getEffectiveTier follows CountsAs to the tier a rank actually bids in
+func getEffectiveTier(rank DKPRank) Tier {
+	tiers := getTiers()
+	tier := getTier(rank)
+	for hops := 0; tier.CountsAs != "" && hops < len(tiers); hops++ { // hop limit guards against loops in the config
+		next, ok := findTier(tiers, tier.CountsAs)
+		if !ok {
+			break
+		}
+		tier = next
+	}
+	return tier
+}
+
+// tierPriority is the priority a rank bids at
+func tierPriority(rank DKPRank) int {
+	return getEffectiveTier(rank).Priority
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestCustomTiers(t *testing.T) {
+	configuration.Tiers = []Tier{
+		{Name: "Main", Priority: 10},
+		{Name: "Box", Priority: 5, MaxBid: 100, CountsAs: "Main"},
+		{Name: "Trial Raider", Priority: 8},
+		{Name: "Inactive", Priority: 0},
+	}
+	defer func() { configuration.Tiers = nil }()
+	trial, err := parseDKPRank("trial raider")
+	if err != nil {
+		t.Fatalf("parseDKPRank(\"trial raider\") error: %s", err)
+	}
+	box, _ := parseDKPRank("Box")
+	if got := DKPRankToString(trial); got != "Trial Raider" {
+		t.Errorf("DKPRankToString(trial) = %s; want Trial Raider", got)
+	}
+	holders := map[string]DKPRank{"Tiermain": MAIN, "Tierbox": box, "Tiertrial": trial, "Tiersocial": SOCIAL}
+	bids := map[string]int{"Tiermain": 150, "Tierbox": 300, "Tiertrial": 500, "Tiersocial": 900}
+	bid := &OpenBid{Quantity: 1}
+	for name, rank := range holders {
+		Roster[name] = &DKPHolder{GuildMember: everquest.GuildMember{Name: name}, DKP: 1000, DKPRank: rank}
+		bid.Bidders = append(bid.Bidders, &Bidder{Player: Roster[name], AttemptedBid: bids[name]})
+	}
+	bid.ApplyDKP()
+	bid.SortBids()
+	var got []string
+	for _, bidder := range bid.Bidders {
+		got = append(got, bidder.Player.Name)
+	}
+	want := []string{"Tiermain", "Tierbox", "Tiertrial", "Tiersocial"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SortBids() = %v; want %v", got, want)
+			break
+		}
+	}
+	if bid.Bidders[1].Bid != 100 {
+		t.Errorf("Box bid = %d; want capped at 100", bid.Bidders[1].Bid)
+	}
+	if GetEffectiveDKPRank(box) != MAIN {
+		t.Errorf("GetEffectiveDKPRank(box) = %s; want Main", DKPRankToString(GetEffectiveDKPRank(box)))
+	}
+}
+
+func TestValidateTiers(t *testing.T) {
+	err := validateTiers([]Tier{{Name: "Box", CountsAs: "Emperor"}})
+	if err == nil {
+		t.Errorf("validateTiers() with unknown CountsAs returned no error")
+	}
+}
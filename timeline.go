@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bid event types, in the order a bidder usually goes through them
+const (
+	BidPlaced    = "placed"
+	BidRaised    = "raised"
+	BidLowered   = "lowered"
+	BidResent    = "resent"
+	BidCancelled = "cancelled"
+)
+
+// BidEvent is one change a player made to their bid, kept so the archive shows more than the final state
+type BidEvent struct {
+	Type    string    `json:"Type"`
+	Amount  int       `json:"Amount"`
+	Message string    `json:"Message"`
+	Time    time.Time `json:"Time"`
+}
+
+// bidChange names the event for a rebid from one amount to another
+func bidChange(from, to int) string {
+	switch {
+	case to > from:
+		return BidRaised
+	case to < from:
+		return BidLowered
+	}
+	return BidResent
+}
+
+// takeCancelled removes a player from the cancelled bidders so a new bid keeps their timeline
+func (b *OpenBid) takeCancelled(name string) *Bidder {
+	for i, bidder := range b.Cancelled {
+		if bidder.Player.Name == name {
+			b.Cancelled = append(b.Cancelled[:i], b.Cancelled[i+1:]...)
+			return bidder
+		}
+	}
+	return nil
+}
+
+type timelineEntry struct {
+	Player string
+	Event  BidEvent
+}
+
+// renderTimeline lists every bid event in an archive in the order they happened
+func renderTimeline(arc BidInvestigation) string {
+	var entries []timelineEntry
+	for _, bidders := range [][]InvestigationBidder{arc.Bidders, arc.Cancelled} {
+		for _, bidder := range bidders {
+			for _, event := range bidder.Events {
+				entries = append(entries, timelineEntry{Player: bidder.Player, Event: event})
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Event.Time.Before(entries[j].Event.Time) })
+	var lines []string
+	for _, entry := range entries {
+		line := fmt.Sprintf("%s %s %s", entry.Event.Time.Format("15:04:05"), entry.Player, entry.Event.Type)
+		if entry.Event.Type != BidCancelled {
+			line += fmt.Sprintf(" %d", entry.Event.Amount)
+		}
+		lines = append(lines, line)
+	}
+	timeline := strings.Join(lines, "\n")
+	const discordLimit = 1900 // leave room for the header
+	timeline = strings.TrimSuffix(cutLines(timeline, discordLimit), "\n")
+	return fmt.Sprintf("**Bid timeline for %s (x%d)**\n```\n%s\n```", arc.ItemName, arc.Quantity, timeline)
+}
+
+// uploadTimeline posts the bid timeline of an archive next to its investigation
+func uploadTimeline(id string) {
+	arc, err := loadArchive(id)
+	if err != nil {
+		Err.Printf("Error reading archive %s for the timeline: %s", id, err.Error())
+		return
+	}
+	if timeline := renderTimeline(arc); timeline != "" {
+		DiscordF(configuration.Discord.InvestigationChannelID, "%s", timeline)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	everquest "github.com/Mortimus/goEverquest"
+)
+
+func TestBidTimeline(t *testing.T) {
+	id, _ := itemDB.FindIDByName("Cloth Cap")
+	item, _ := itemDB.GetItemByID(id)
+	player := DKPHolder{GuildMember: everquest.GuildMember{Name: "Timeliner"}, DKP: 500, DKPRank: MAIN}
+	bid := &OpenBid{Item: item, Quantity: 1}
+	start := time.Now()
+	bid.AddBid(player, 50, everquest.EqLog{Msg: "Cloth Cap 50", T: start})
+	bid.AddBid(player, 100, everquest.EqLog{Msg: "Cloth Cap 100", T: start.Add(time.Second)})
+	bid.AddBid(player, 75, everquest.EqLog{Msg: "Cloth Cap 75", T: start.Add(2 * time.Second)})
+	bid.AddBid(player, 0, everquest.EqLog{Msg: "Cloth Cap 0", T: start.Add(3 * time.Second)})
+	if len(bid.Cancelled) != 1 {
+		t.Fatalf("len(bid.Cancelled) = %d, want 1", len(bid.Cancelled))
+	}
+	bid.AddBid(player, 60, everquest.EqLog{Msg: "Cloth Cap 60", T: start.Add(4 * time.Second)})
+	if len(bid.Cancelled) != 0 {
+		t.Errorf("len(bid.Cancelled) = %d after rebidding, want 0", len(bid.Cancelled))
+	}
+	pos := bid.FindBid("Timeliner")
+	if pos < 0 {
+		t.Fatalf("bid.FindBid() = %d, want the rebid bidder", pos)
+	}
+	var got []string
+	for _, event := range bid.Bidders[pos].Events {
+		got = append(got, event.Type)
+	}
+	want := []string{BidPlaced, BidRaised, BidLowered, BidCancelled, BidPlaced}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Events = %v, want %v", got, want)
+	}
+	timeline := renderTimeline(BidInvestigation{ItemName: item.Name, Quantity: 1, Bidders: []InvestigationBidder{bid.investigationBidder(bid.Bidders[pos])}})
+	if !strings.Contains(timeline, "Timeliner lowered 75") || !strings.Contains(timeline, "Timeliner cancelled") {
+		t.Errorf("renderTimeline() = %q, want the lowered and cancelled events", timeline)
+	}
+}
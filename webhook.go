@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"time"
+)
+
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// WebhookIdentity is the name and avatar a plugin posts as on webhook sinks
+type WebhookIdentity struct {
+	Username  string
+	AvatarURL string
+}
+
+// pluginIdentities are plugins that post to webhooks under their own name or avatar
+var pluginIdentities = make(map[LogHandler]WebhookIdentity)
+
+// WebhookWriter posts to a discord webhook, for channels in servers the bot is not in
+type WebhookWriter struct {
+	URL string
+	WebhookIdentity
+}
+
+type webhookPayload struct {
+	Content   string `json:"content,omitempty"`
+	Username  string `json:"username,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+// webhookError is a webhook post discord refused
+type webhookError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *webhookError) Error() string {
+	return fmt.Sprintf("webhook returned %s: %s", e.Status, e.Body)
+}
+
+// Write for WebhookWriter queues the message on the outbox like DiscordWriter
+func (ww *WebhookWriter) Write(p []byte) (n int, err error) {
+	for _, chunk := range splitMessage(string(p), maxMessageLength) {
+		err = outbox.Enqueue(ww.URL, chunk, ww.send)
+		if err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+func (ww *WebhookWriter) send(content string) error {
+	body, err := json.Marshal(webhookPayload{Content: content, Username: ww.Username, AvatarURL: ww.AvatarURL})
+	if err != nil {
+		return err
+	}
+	return ww.post("application/json", bytes.NewReader(body))
+}
+
+// SendFile for WebhookWriter queues a file attached to a webhook message behind the webhook's messages
+func (ww *WebhookWriter) SendFile(name string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r) // the caller may close r before the upload is sent
+	if err != nil {
+		return err
+	}
+	return outbox.Call(ww.URL, name, func() error {
+		return ww.sendFile(name, data)
+	})
+}
+
+func (ww *WebhookWriter) sendFile(name string, data []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	payload, err := json.Marshal(webhookPayload{Username: ww.Username, AvatarURL: ww.AvatarURL})
+	if err != nil {
+		return err
+	}
+	err = form.WriteField("payload_json", string(payload))
+	if err != nil {
+		return err
+	}
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	if err != nil {
+		return err
+	}
+	err = form.Close()
+	if err != nil {
+		return err
+	}
+	return ww.post(form.FormDataContentType(), &body)
+}
+
+func (ww *WebhookWriter) post(contentType string, body io.Reader) error {
+	resp, err := webhookClient.Post(ww.URL, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return &webhookError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(msg), RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	return nil
+}
+
+// as copies a route with its webhook sinks posting under another name or avatar
+func (rw *RouteWriter) as(identity WebhookIdentity) *RouteWriter {
+	copied := &RouteWriter{Name: rw.Name}
+	for _, sink := range rw.Sinks {
+		if webhook, ok := sink.(*WebhookWriter); ok {
+			named := *webhook
+			if identity.Username != "" {
+				named.Username = identity.Username
+			}
+			if identity.AvatarURL != "" {
+				named.AvatarURL = identity.AvatarURL
+			}
+			sink = &named
+		}
+		copied.Sinks = append(copied.Sinks, sink)
+	}
+	return copied
+}
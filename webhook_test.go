@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookWriterPostsAsPlugin(t *testing.T) {
+	posted := make(chan webhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+		posted <- payload
+	}))
+	defer server.Close()
+	route := &RouteWriter{Name: "raid", Sinks: []io.Writer{&WebhookWriter{URL: server.URL}}}
+	named := route.as(WebhookIdentity{Username: "Raid Bot", AvatarURL: "https://example.com/raid.png"})
+	_, err := named.Write([]byte("Vex Thal boss down\n"))
+	if err != nil {
+		t.Fatalf("named.Write() error = %s", err)
+	}
+	var got webhookPayload
+	select {
+	case got = <-posted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook was not posted to")
+	}
+	want := webhookPayload{Content: "Vex Thal boss down\n", Username: "Raid Bot", AvatarURL: "https://example.com/raid.png"}
+	if got != want {
+		t.Errorf("payload = %#v, want %#v", got, want)
+	}
+	if route.Sinks[0].(*WebhookWriter).Username != "" {
+		t.Errorf("route.as changed the shared route's webhook")
+	}
+}
+
+func TestWebhookWriterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unknown Webhook", http.StatusNotFound)
+	}))
+	defer server.Close()
+	ww := &WebhookWriter{URL: server.URL}
+	err := ww.send("hello")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("ww.send() error = %v, want a 404 error", err)
+	}
+	if _, retry := retryDelay(err, 1); retry {
+		t.Errorf("retryDelay(%v) retries, want an unknown webhook to be given up on", err)
+	}
+}
+
+func TestRouteSendFileToWebhook(t *testing.T) {
+	type upload struct{ name, file, payload string }
+	uploaded := make(chan upload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		part, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("r.FormFile() error = %s", err)
+			return
+		}
+		data, _ := ioutil.ReadAll(part)
+		uploaded <- upload{header.Filename, string(data), r.FormValue("payload_json")}
+	}))
+	defer server.Close()
+	var text bytes.Buffer
+	route := &RouteWriter{Name: "dumps", Sinks: []io.Writer{&text, &WebhookWriter{URL: server.URL, WebhookIdentity: WebhookIdentity{Username: "Dumps"}}}}
+	err := route.SendFile("20210417_raid_start.txt", strings.NewReader("1\tMortimus\t65\tNecromancer"))
+	if err != nil {
+		t.Fatalf("route.SendFile() error = %s", err)
+	}
+	var got upload
+	select {
+	case got = <-uploaded:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("file was not uploaded to the webhook")
+	}
+	if got.name != "20210417_raid_start.txt" || got.file != "1\tMortimus\t65\tNecromancer" {
+		t.Errorf("webhook got file %q = %q", got.name, got.file)
+	}
+	if !strings.Contains(got.payload, `"username":"Dumps"`) {
+		t.Errorf("payload_json = %q, want the username", got.payload)
+	}
+	if text.Len() != 0 {
+		t.Errorf("text sink got %q, sinks without files should be skipped", text.String())
+	}
+}
@@ -3,9 +3,6 @@ package main
 import (
 	"fmt"
 	"io"
-	"strings"
-
-	everquest "github.com/Mortimus/goEverquest"
 )
 
 var currentZone string
@@ -18,13 +15,16 @@ func init() {
 	ldplug.Author = "Mortimus"
 	ldplug.Version = "1.0.0"
 	ldplug.Output = STDOUT
-	Handlers = append(Handlers, ldplug)
+	registerPlugin("zone", ldplug)
+}
+
+func (p *ZonePlugin) Subscriptions() []EventType {
+	return []EventType{EventZoned}
 }
 
-// Handle for LinkdeadPlugin sends a message if it detects a player has gone linkdead.
-func (p *ZonePlugin) Handle(msg *everquest.EqLog, out io.Writer) {
-	if msg.Channel == "system" && strings.Contains(msg.Msg, "You have entered ") && !strings.Contains(msg.Msg, "function.") && !strings.Contains(msg.Msg, "Bind Affinity") { // You have entered Vex Thal. NOT You have entered an area where levitation effects do not function.
-		currentZone = msg.Msg[17 : len(msg.Msg)-1]
+func (p *ZonePlugin) HandleEvent(event Event, out io.Writer) {
+	if e, ok := event.(ZonedEvent); ok {
+		currentZone = e.Zone
 		// fmt.Fprintf(out, "Changing zone to %s\n", currentZone)
 	}
 }
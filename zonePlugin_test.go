@@ -17,11 +17,11 @@ func TestZone(t *testing.T) {
 	msg.T = time.Now()
 	currentZone = ""
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := currentZone
 	want := "Vex Thal"
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -34,11 +34,11 @@ func TestZoneLevitate(t *testing.T) {
 	msg.T = time.Now()
 	currentZone = ""
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }
 
@@ -51,10 +51,10 @@ func TestZoneBind(t *testing.T) {
 	msg.T = time.Now()
 	currentZone = ""
 	var b bytes.Buffer
-	ldplug.Handle(msg, &b)
+	publishLog(ldplug, msg, &b)
 	got := b.String()
 	want := ""
 	if got != want {
-		t.Errorf("ldplug.Handle(msg, &b) = %q, want %q", got, want)
+		t.Errorf("publishLog(ldplug, msg, &b) = %q, want %q", got, want)
 	}
 }